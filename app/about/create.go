@@ -1,15 +1,21 @@
-//go:build js && wasm
-
 package about
 
 import (
 	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/forms"
 	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
 	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
 	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
 	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
 )
 
+// createForm declares the fields of the create form
+var createForm = forms.New("about-create",
+	forms.Field{Name: "title", Label: "Title", Placeholder: "My item", Rules: []forms.Rule{forms.Required(), forms.MinLength(3), forms.MaxLength(80)}},
+	forms.Field{Name: "email", Label: "Contact email", Type: "email", Placeholder: "you@example.com", Rules: []forms.Rule{forms.Required(), forms.Email()}},
+	forms.Field{Name: "description", Label: "Description", Type: "textarea", Rules: []forms.Rule{forms.MaxLength(500)}},
+).SubmitText("Create").PendingText("Creating…")
+
 // AboutCreatePage represents the create page for about
 type AboutCreatePage struct {
 	framework.BasePage
@@ -20,6 +26,11 @@ func (p *AboutCreatePage) GetInitialState() map[string]interface{} {
 }
 
 func (p *AboutCreatePage) Render() string {
+	success := ""
+	if createForm.Submitted() {
+		success = StencilPage.Alert("Item created.", "success", false)
+	}
+
 	content := StencilUtils.Join(
 		StencilText.Titre1("Create New About", "text-center", "text-success", "mb-4"),
 		StencilText.Paragraphe("Create a new item in about", "text-center", "lead", "mb-4"),
@@ -27,7 +38,8 @@ func (p *AboutCreatePage) Render() string {
 		StencilPage.Div(
 			StencilUtils.Join(
 				StencilText.Titre2("Create Form", "mb-3"),
-				StencilText.Paragraphe("Add your form components here.", "mb-3"),
+				success,
+				createForm.View(),
 			),
 			"bg-success", "bg-opacity-10", "p-4", "rounded", "mb-4",
 		),
@@ -1,15 +1,21 @@
-//go:build js && wasm
-
 package about
 
 import (
 	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/forms"
 	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
 	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
 	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
 	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
 )
 
+// editForm declares the fields of the edit form, prefilled with the current item
+var editForm = forms.New("about-edit",
+	forms.Field{Name: "title", Label: "Title", Initial: "About Stencil", Rules: []forms.Rule{forms.Required(), forms.MinLength(3), forms.MaxLength(80)}},
+	forms.Field{Name: "description", Label: "Description", Type: "textarea", Initial: "A Go framework for WebAssembly apps.", Rules: []forms.Rule{forms.MaxLength(500)}},
+	forms.Field{Name: "published", Label: "Published", Type: "checkbox", Initial: "true"},
+).SubmitText("Save").PendingText("Saving…")
+
 // AboutEditPage represents the edit page for about
 type AboutEditPage struct {
 	framework.BasePage
@@ -20,6 +26,11 @@ func (p *AboutEditPage) GetInitialState() map[string]interface{} {
 }
 
 func (p *AboutEditPage) Render() string {
+	success := ""
+	if editForm.Submitted() {
+		success = StencilPage.Alert("Changes saved.", "success", false)
+	}
+
 	content := StencilUtils.Join(
 		StencilText.Titre1("Edit About", "text-center", "text-warning", "mb-4"),
 		StencilText.Paragraphe("Edit an existing item in about", "text-center", "lead", "mb-4"),
@@ -27,7 +38,8 @@ func (p *AboutEditPage) Render() string {
 		StencilPage.Div(
 			StencilUtils.Join(
 				StencilText.Titre2("Edit Form", "mb-3"),
-				StencilText.Paragraphe("Add your form components here.", "mb-3"),
+				success,
+				editForm.View(),
 			),
 			"bg-warning", "bg-opacity-10", "p-4", "rounded", "mb-4",
 		),
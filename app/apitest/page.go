@@ -1,10 +1,7 @@
-//go:build js && wasm
-
 package apitest
 
 import (
 	"fmt"
-	"syscall/js"
 
 	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
 	"github.com/RafaelCoppe/Stencil-Framework/core/http"
@@ -36,7 +33,7 @@ func (p *ApitestPage) GetInitialState() map[string]interface{} {
 	}
 }
 
-func (p *ApitestPage) HandleEvent(eventName string, event js.Value) {
+func (p *ApitestPage) HandleEvent(eventName string, event framework.Event) {
 	switch eventName {
 	case "loadTodos":
 		p.loadTodos()
@@ -1,12 +1,6 @@
-//go:build js && wasm
-
 package app
 
 import (
-	"syscall/js"
-
-	"github.com/RafaelCoppe/Stencil-Framework/app/about"
-	"github.com/RafaelCoppe/Stencil-Framework/app/apitest"
 	"github.com/RafaelCoppe/Stencil-Framework/components"
 	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
 	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
@@ -26,7 +20,7 @@ func (p *WelcomePage) GetInitialState() map[string]interface{} {
 	}
 }
 
-func (p *WelcomePage) HandleEvent(eventName string, event js.Value) {
+func (p *WelcomePage) HandleEvent(eventName string, event framework.Event) {
 	switch eventName {
 	case "toggleDetails":
 		showDetails := framework.GetStateBool("showDetails")
@@ -135,6 +129,22 @@ func (p *WelcomePage) Render() string {
 		"p-8", "border-b",
 	)
 
+	// Stateful components section: two instances of the same component
+	statefulComponents := StencilPage.Div(
+		StencilUtils.Join(
+			StencilText.Titre2("🧩 Stateful Components", "text-3xl", "font-bold", "text-gray-800", "mb-4", "flex", "items-center"),
+			StencilText.Paragraphe("Each counter keeps its own state and handles its own events:", "text-gray-600", "mb-6"),
+			StencilPage.Div(
+				StencilUtils.Join(
+					framework.Embed("likes", &components.Counter{}, framework.Props{"label": "👍 Likes", "start": 3}),
+					framework.Embed("stars", &components.Counter{}, framework.Props{"label": "⭐ Stars"}),
+				),
+				"grid", "md:grid-cols-2", "gap-6",
+			),
+		),
+		"p-8", "border-b",
+	)
+
 	// Toggle button section
 	toggleButton := StencilPage.Div(
 		StencilInteractions.Bouton(
@@ -196,6 +206,7 @@ func (p *WelcomePage) Render() string {
 			navigationDemo,
 			gettingStarted,
 			features,
+			statefulComponents,
 			toggleButton,
 			detailsSection,
 		),
@@ -215,25 +226,6 @@ func (p *WelcomePage) Render() string {
 	)
 }
 
-// RegisterRoutes registers all routes for this application
-func RegisterRoutes() {
-	// Register the welcome page route
-	framework.RegisterPageRoute("/",
-		func() framework.PageInterface { return &WelcomePage{} },
-		nil, // create.go handler
-		nil, // edit.go handler
-	)
-
-	// Register about page routes
-	framework.RegisterPageRoute("/about",
-		func() framework.PageInterface { return &about.AboutPage{} },
-		func() framework.PageInterface { return &about.AboutCreatePage{} },
-		func() framework.PageInterface { return &about.AboutEditPage{} },
-	)
-
-	framework.RegisterPageRoute("/apitest",
-		func() framework.PageInterface { return &apitest.ApitestPage{} },
-		nil,
-		nil,
-	)
-}
+// RegisterRoutes is generated in routes_gen.go from the app directory:
+// run go generate ./app after adding a page or a layout.
+//go:generate go run github.com/RafaelCoppe/Stencil-Framework/core/cmd/routegen
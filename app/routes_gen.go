@@ -0,0 +1,28 @@
+// Code generated by routegen from the app directory. DO NOT EDIT.
+
+package app
+
+import (
+	"github.com/RafaelCoppe/Stencil-Framework/app/about"
+	"github.com/RafaelCoppe/Stencil-Framework/app/apitest"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+)
+
+// RegisterRoutes registers the pages and layouts of the app directory
+func RegisterRoutes() {
+	framework.RegisterPageRoute("/",
+		func() framework.PageInterface { return &WelcomePage{} },
+		nil,
+		nil,
+	)
+	framework.RegisterPageRoute("/about",
+		func() framework.PageInterface { return &about.AboutPage{} },
+		func() framework.PageInterface { return &about.AboutCreatePage{} },
+		func() framework.PageInterface { return &about.AboutEditPage{} },
+	)
+	framework.RegisterPageRoute("/apitest",
+		func() framework.PageInterface { return &apitest.ApitestPage{} },
+		nil,
+		nil,
+	)
+}
@@ -0,0 +1,60 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
+	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
+	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
+	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
+)
+
+// Counter est un composant avec état : chaque instance a son propre compteur
+//
+//	framework.Embed("likes", &components.Counter{}, framework.Props{"label": "J'aime", "start": 3})
+type Counter struct {
+	framework.BaseComponent
+}
+
+// GetInitialState initialise le compteur avec la prop "start"
+func (c *Counter) GetInitialState(props framework.Props) map[string]interface{} {
+	start, _ := props["start"].(int)
+	return map[string]interface{}{
+		"count": start,
+	}
+}
+
+// HandleEvent gère les boutons de l'instance
+func (c *Counter) HandleEvent(ctx *framework.ComponentContext, eventName string, event framework.Event) {
+	switch eventName {
+	case "increment":
+		ctx.Set("count", ctx.GetInt("count")+1)
+	case "decrement":
+		ctx.Set("count", ctx.GetInt("count")-1)
+	case "reset":
+		ctx.Set("count", 0)
+		ctx.Emit("reset", event)
+	}
+}
+
+// Render affiche le compteur et ses boutons
+func (c *Counter) Render(ctx *framework.ComponentContext) string {
+	label := ctx.Prop("label", "Compteur")
+
+	return StencilPage.Div(
+		StencilUtils.Join(
+			StencilText.Paragraphe(label, "font-semibold", "text-gray-800", "mb-2"),
+			StencilText.Paragraphe(fmt.Sprintf("%d", ctx.GetInt("count")), "text-3xl", "font-bold", "text-blue-600", "mb-4"),
+			StencilPage.Div(
+				StencilUtils.Join(
+					StencilInteractions.Bouton("−", "decrement", "bg-gray-200", "px-4", "py-2", "rounded-lg"),
+					StencilInteractions.Bouton("+", "increment", "bg-blue-500", "text-white", "px-4", "py-2", "rounded-lg"),
+					StencilInteractions.Bouton("Reset", "reset", "bg-gray-100", "px-4", "py-2", "rounded-lg"),
+				),
+				"flex", "justify-center", "gap-2",
+			),
+		),
+		"text-center", "p-6", "bg-gradient-to-br", "from-blue-50", "to-blue-100", "rounded-xl",
+	)
+}
@@ -2,6 +2,9 @@ module github.com/RafaelCoppe/Stencil-Framework/components
 
 go 1.24.1
 
+replace github.com/RafaelCoppe/Stencil-Framework/core/framework => ../core/framework
+
 require (
+	github.com/RafaelCoppe/Stencil-Framework/core/framework v0.0.0-00010101000000-000000000000
 	github.com/RafaelCoppe/Stencil-Go v1.1.0
 )
@@ -1,8 +1,11 @@
+//go:build !(js && wasm)
+
 package main
 
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -21,6 +24,14 @@ func main() {
 			return
 		}
 		createRoute(os.Args[2])
+	case "create-layout":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: go run cmd/cli.go create-layout <route-path>")
+			return
+		}
+		createLayout(os.Args[2])
+	case "export":
+		exportSite(os.Args[2:])
 	default:
 		printUsage()
 	}
@@ -31,10 +42,29 @@ func printUsage() {
 	fmt.Println("")
 	fmt.Println("Usage:")
 	fmt.Println("  go run cmd/cli.go create-route <route-path>")
+	fmt.Println("  go run cmd/cli.go create-layout <route-path>")
+	fmt.Println("  go run cmd/cli.go export [-out dist] [-base-url https://example.com]")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  go run cmd/cli.go create-route users")
 	fmt.Println("  go run cmd/cli.go create-route admin/dashboard")
+	fmt.Println("  go run cmd/cli.go create-route users/:id")
+	fmt.Println("  go run cmd/cli.go create-route docs/*slug")
+	fmt.Println("  go run cmd/cli.go create-layout admin")
+	fmt.Println("  go run cmd/cli.go export -base-url https://example.com")
+}
+
+// exportSite runs the export command, which builds against the app package.
+// It runs in its own process so that the scaffolding commands keep working
+// while the app does not compile.
+func exportSite(args []string) {
+	cmd := exec.Command("go", append([]string{"run", "./core/cmd/export"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error exporting site: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func createRoute(routePath string) {
@@ -45,8 +75,8 @@ func createRoute(routePath string) {
 		return
 	}
 
-	// Create the directory
-	dirPath := filepath.Join("app", routePath)
+	// Parameter segments (:id, [id], *slug, [...slug]...) get their own directory
+	routePath, dirPath, params := parseRoutePath(routePath)
 	err := os.MkdirAll(dirPath, 0755)
 	if err != nil {
 		fmt.Printf("Error creating directory: %v\n", err)
@@ -54,12 +84,12 @@ func createRoute(routePath string) {
 	}
 
 	// Generate package name
-	packageName := filepath.Base(routePath)
+	packageName := strings.Trim(filepath.Base(dirPath), "_")
 	packageName = strings.ReplaceAll(packageName, "-", "")
 	packageName = strings.ReplaceAll(packageName, "_", "")
 
 	// Create page.go
-	pageContent := generatePageContent(packageName, routePath)
+	pageContent := generatePageContent(packageName, routePath, params)
 	pageFile := filepath.Join(dirPath, "page.go")
 	err = os.WriteFile(pageFile, []byte(pageContent), 0644)
 	if err != nil {
@@ -91,22 +121,123 @@ func createRoute(routePath string) {
 	fmt.Printf("  - %s\n", pageFile)
 	fmt.Printf("  - %s\n", createFile)
 	fmt.Printf("  - %s\n", editFile)
-	fmt.Printf("\n💡 Don't forget to register the route in your main RegisterRoutes() function:\n")
-	fmt.Printf("framework.RegisterPageRoute(\"/%s\",\n", routePath)
-	fmt.Printf("    func() framework.PageInterface { return &%s.%sPage{} },\n", packageName, strings.Title(packageName))
-	fmt.Printf("    func() framework.PageInterface { return &%s.%sCreatePage{} },\n", packageName, strings.Title(packageName))
-	fmt.Printf("    func() framework.PageInterface { return &%s.%sEditPage{} },\n", packageName, strings.Title(packageName))
-	fmt.Printf(")\n")
+	for _, param := range params {
+		fmt.Printf("🔗 Parameter: %s, read it with framework.Param(%q)\n", param, param)
+	}
+	generateRoutes()
 }
 
-func generatePageContent(packageName, routePath string) string {
-	title := strings.Title(strings.ReplaceAll(packageName, "-", " "))
-	return fmt.Sprintf(`//go:build js && wasm
+func createLayout(routePath string) {
+	// The root layout, wrapping every page, lives in app/
+	routePath = strings.Trim(routePath, "/")
+	dirPath, packageName := "app", "app"
+	if routePath != "" {
+		routePath, dirPath, _ = parseRoutePath(routePath)
+		packageName = strings.Trim(filepath.Base(dirPath), "_")
+		packageName = strings.ReplaceAll(packageName, "-", "")
+		packageName = strings.ReplaceAll(packageName, "_", "")
+	}
+
+	layoutFile := filepath.Join(dirPath, "layout.go")
+	if _, err := os.Stat(layoutFile); err == nil {
+		fmt.Printf("Error: %s already exists\n", layoutFile)
+		return
+	}
+	err := os.MkdirAll(dirPath, 0755)
+	if err != nil {
+		fmt.Printf("Error creating directory: %v\n", err)
+		return
+	}
 
-package %s
+	layoutContent := generateLayoutContent(packageName, routePath)
+	err = os.WriteFile(layoutFile, []byte(layoutContent), 0644)
+	if err != nil {
+		fmt.Printf("Error creating layout.go: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Layout created successfully!\n")
+	fmt.Printf("📁 Directory: %s\n", dirPath)
+	fmt.Printf("📄 Files created:\n")
+	fmt.Printf("  - %s\n", layoutFile)
+	generateRoutes()
+}
+
+// generateRoutes registers the new files by running the route generator of
+// the app directory, see core/cmd/routegen
+func generateRoutes() {
+	cmd := exec.Command("go", "generate", "./app")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("\n⚠️  Could not update app/routes_gen.go: %v\n", err)
+		fmt.Printf("💡 Run go generate ./app once the error is fixed\n")
+		return
+	}
+	fmt.Printf("\n🔗 Registered in app/routes_gen.go\n")
+}
+
+// parseRoutePath turns the segments :id and [id] into route parameters stored
+// in an _id directory, and the optional :id? and [[id]] into an _id_ directory.
+// Catch-alls, *slug and [...slug], go to a __slug directory, and optional
+// catch-alls, *slug? and [[...slug]], to __slug_.
+// It returns the route pattern, the directory of the package and the parameters.
+func parseRoutePath(routePath string) (pattern, dirPath string, params []string) {
+	segments := strings.Split(routePath, "/")
+	dirs := []string{"app"}
+	for i, segment := range segments {
+		name, optional := segment, false
+		switch {
+		case strings.HasPrefix(segment, "[[") && strings.HasSuffix(segment, "]]"):
+			name, optional = segment[2:len(segment)-2], true
+		case strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]"):
+			name = segment[1 : len(segment)-1]
+		case (strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")) && strings.HasSuffix(segment, "?"):
+			name, optional = segment[:len(segment)-1], true
+		case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+			name = segment
+		default:
+			dirs = append(dirs, segment)
+			continue
+		}
+
+		marker, dir := ":", "_"
+		if strings.HasPrefix(name, "...") || strings.HasPrefix(name, "*") {
+			marker, dir = "*", "__"
+		}
+		name = strings.TrimLeft(name, ":*.")
+
+		params = append(params, name)
+		segments[i] = marker + name
+		dir += name
+		if optional {
+			segments[i] += "?"
+			dir += "_"
+		}
+		dirs = append(dirs, dir)
+	}
+	return strings.Join(segments, "/"), filepath.Join(dirs...), params
+}
+
+// linkTo returns the Go expression of a link to path, built from the current
+// route parameters when path has any
+func linkTo(path string) string {
+	if strings.Contains(path, "/:") || strings.Contains(path, "/*") {
+		return fmt.Sprintf("framework.BuildPath(%q, framework.Params())", path)
+	}
+	return fmt.Sprintf("%q", path)
+}
+
+func generatePageContent(packageName, routePath string, params []string) string {
+	title := strings.Title(strings.ReplaceAll(packageName, "-", " "))
+	paramLines := ""
+	for _, param := range params {
+		paramLines += fmt.Sprintf("\n\t\tStencilText.Paragraphe(%q+framework.Param(%q), \"text-center\", \"text-muted\"),", param+": ", param)
+	}
+	return fmt.Sprintf(`package %s
 
 import (
-	"github.com/RafaelCoppe/Stencil-Framework/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
 	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
 	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
 	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
@@ -125,13 +256,13 @@ func (p *%sPage) GetInitialState() map[string]interface{} {
 func (p *%sPage) Render() string {
 	content := StencilUtils.Join(
 		StencilText.Titre1("%s", "text-center", "text-primary", "mb-4"),
-		StencilText.Paragraphe("Welcome to the %s page!", "text-center", "lead", "mb-4"),
+		StencilText.Paragraphe("Welcome to the %s page!", "text-center", "lead", "mb-4"),%s
 		
 		StencilPage.Div(
 			StencilUtils.Join(
 				StencilText.Titre2("Page Actions", "mb-3"),
-				StencilInteractions.Lien("/%s/create", "Create New", "btn", "btn-success", "me-2"),
-				StencilInteractions.Lien("/%s/edit", "Edit", "btn", "btn-warning", "me-2"),
+				StencilInteractions.Lien(%s, "Create New", "btn", "btn-success", "me-2"),
+				StencilInteractions.Lien(%s, "Edit", "btn", "btn-warning", "me-2"),
 				StencilInteractions.Lien("/", "← Back to Home", "btn", "btn-secondary"),
 			),
 			"text-center", "bg-light", "p-4", "rounded",
@@ -140,23 +271,28 @@ func (p *%sPage) Render() string {
 
 	return StencilPage.Container(content, "container", "my-5")
 }
-`, packageName, strings.Title(packageName), routePath, strings.Title(packageName), strings.Title(packageName), strings.Title(packageName), title, routePath, routePath, routePath)
+`, packageName, strings.Title(packageName), routePath, strings.Title(packageName), strings.Title(packageName), strings.Title(packageName), title, routePath, paramLines, linkTo("/"+routePath+"/create"), linkTo("/"+routePath+"/edit"))
 }
 
 func generateCreateContent(packageName, routePath string) string {
 	title := strings.Title(strings.ReplaceAll(packageName, "-", " "))
-	return fmt.Sprintf(`//go:build js && wasm
-
-package %s
+	return fmt.Sprintf(`package %s
 
 import (
-	"github.com/RafaelCoppe/Stencil-Framework/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/forms"
 	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
 	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
 	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
 	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
 )
 
+// createForm declares the fields of the create form
+var createForm = forms.New("%s-create",
+	forms.Field{Name: "title", Label: "Title", Rules: []forms.Rule{forms.Required(), forms.MaxLength(80)}},
+	forms.Field{Name: "description", Label: "Description", Type: "textarea", Rules: []forms.Rule{forms.MaxLength(500)}},
+).SubmitText("Create").PendingText("Creating…")
+
 // %sCreatePage represents the create page for %s
 type %sCreatePage struct {
 	framework.BasePage
@@ -174,14 +310,14 @@ func (p *%sCreatePage) Render() string {
 		StencilPage.Div(
 			StencilUtils.Join(
 				StencilText.Titre2("Create Form", "mb-3"),
-				StencilText.Paragraphe("Add your form components here.", "mb-3"),
+				createForm.View(),
 			),
 			"bg-success", "bg-opacity-10", "p-4", "rounded", "mb-4",
 		),
 		
 		StencilPage.Div(
 			StencilUtils.Join(
-				StencilInteractions.Lien("/%s", "← Back to %s", "btn", "btn-secondary", "me-2"),
+				StencilInteractions.Lien(%s, "← Back to %s", "btn", "btn-secondary", "me-2"),
 				StencilInteractions.Lien("/", "Home", "btn", "btn-primary"),
 			),
 			"text-center",
@@ -190,23 +326,28 @@ func (p *%sCreatePage) Render() string {
 
 	return StencilPage.Container(content, "container", "my-5")
 }
-`, packageName, strings.Title(packageName), routePath, strings.Title(packageName), strings.Title(packageName), strings.Title(packageName), title, routePath, routePath, title)
+`, packageName, packageName, strings.Title(packageName), routePath, strings.Title(packageName), strings.Title(packageName), strings.Title(packageName), title, routePath, linkTo("/"+routePath), title)
 }
 
 func generateEditContent(packageName, routePath string) string {
 	title := strings.Title(strings.ReplaceAll(packageName, "-", " "))
-	return fmt.Sprintf(`//go:build js && wasm
-
-package %s
+	return fmt.Sprintf(`package %s
 
 import (
-	"github.com/RafaelCoppe/Stencil-Framework/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/forms"
 	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
 	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
 	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
 	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
 )
 
+// editForm declares the fields of the edit form
+var editForm = forms.New("%s-edit",
+	forms.Field{Name: "title", Label: "Title", Rules: []forms.Rule{forms.Required(), forms.MaxLength(80)}},
+	forms.Field{Name: "description", Label: "Description", Type: "textarea", Rules: []forms.Rule{forms.MaxLength(500)}},
+).SubmitText("Save").PendingText("Saving…")
+
 // %sEditPage represents the edit page for %s
 type %sEditPage struct {
 	framework.BasePage
@@ -224,14 +365,14 @@ func (p *%sEditPage) Render() string {
 		StencilPage.Div(
 			StencilUtils.Join(
 				StencilText.Titre2("Edit Form", "mb-3"),
-				StencilText.Paragraphe("Add your form components here.", "mb-3"),
+				editForm.View(),
 			),
 			"bg-warning", "bg-opacity-10", "p-4", "rounded", "mb-4",
 		),
 		
 		StencilPage.Div(
 			StencilUtils.Join(
-				StencilInteractions.Lien("/%s", "← Back to %s", "btn", "btn-secondary", "me-2"),
+				StencilInteractions.Lien(%s, "← Back to %s", "btn", "btn-secondary", "me-2"),
 				StencilInteractions.Lien("/", "Home", "btn", "btn-primary"),
 			),
 			"text-center",
@@ -240,5 +381,57 @@ func (p *%sEditPage) Render() string {
 
 	return StencilPage.Container(content, "container", "my-5")
 }
-`, packageName, strings.Title(packageName), routePath, strings.Title(packageName), strings.Title(packageName), strings.Title(packageName), title, routePath, routePath, title)
+`, packageName, packageName, strings.Title(packageName), routePath, strings.Title(packageName), strings.Title(packageName), strings.Title(packageName), title, routePath, linkTo("/"+routePath), title)
+}
+
+func generateLayoutContent(packageName, routePath string) string {
+	title := strings.Title(strings.ReplaceAll(packageName, "-", " "))
+	return fmt.Sprintf(`package %s
+
+import (
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
+	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
+	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
+)
+
+// %sLayout wraps the pages under /%s, it keeps its state while the user navigates between them
+type %sLayout struct {
+	framework.BaseLayout
+}
+
+func (l *%sLayout) GetInitialState() map[string]interface{} {
+	return map[string]interface{}{
+		"menuOpen": true,
+	}
+}
+
+func (l *%sLayout) HandleEvent(ctx *framework.LayoutContext, eventName string, event framework.Event) {
+	switch eventName {
+	case "toggleMenu":
+		ctx.Set("menuOpen", !ctx.GetBool("menuOpen"))
+	}
+}
+
+func (l *%sLayout) Render(ctx *framework.LayoutContext, slot string) string {
+	menu := ""
+	if ctx.GetBool("menuOpen") {
+		menu = StencilPage.Div(
+			StencilUtils.Join(
+				StencilInteractions.Lien(%s, "%s", "nav-link"),
+				StencilInteractions.Lien("/", "Home", "nav-link"),
+			),
+			"nav", "bg-light", "p-2", "mb-3", "rounded",
+		)
+	}
+
+	content := StencilUtils.Join(
+		`+"`"+`<button class="btn btn-outline-secondary btn-sm mb-2" data-onclick="toggleMenu">☰ Menu</button>`+"`"+`,
+		menu,
+		slot,
+	)
+
+	return StencilPage.Container(content, "container", "my-3")
+}
+`, packageName, title, routePath, title, title, title, title, linkTo("/"+routePath), title)
 }
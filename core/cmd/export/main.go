@@ -0,0 +1,54 @@
+//go:build !(js && wasm)
+
+// Command export prerenders every route registered by app.RegisterRoutes into
+// a static site, one index.html per route with its serialized state. It backs
+// the export command of the CLI, which runs it with its arguments:
+//
+//	go run core/cmd/cli.go export -out dist -base-url https://example.com
+//
+// It lives apart from cli.go because it builds against the app package, and
+// the scaffolding commands must keep working while the app does not compile.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/RafaelCoppe/Stencil-Framework/app"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/http"
+)
+
+func main() {
+	outDir := flag.String("out", "dist", "output directory")
+	baseURL := flag.String("base-url", "", "public URL of the site, sitemap.xml is only written when it is set")
+	index := flag.String("index", "core/index.html", "HTML shell the pages are rendered into")
+	staticDir := flag.String("static", "core", "directory holding app.wasm and wasm_exec.js")
+	flag.Parse()
+
+	// Same setup as main.go, for the pages that call the API while rendering
+	http.InitJSONPlaceholder()
+	app.RegisterRoutes()
+
+	files, err := framework.ExportStatic(framework.ExportOptions{
+		OutDir:    *outDir,
+		Index:     *index,
+		StaticDir: *staticDir,
+		BaseURL:   *baseURL,
+	})
+	if err != nil {
+		fmt.Printf("Error exporting site: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Site exported successfully!\n")
+	fmt.Printf("📁 Directory: %s\n", *outDir)
+	fmt.Printf("📄 Files created:\n")
+	for _, file := range files {
+		fmt.Printf("  - %s\n", file)
+	}
+	if *baseURL == "" {
+		fmt.Printf("\n💡 Pass -base-url to also write sitemap.xml\n")
+	}
+}
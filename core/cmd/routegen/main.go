@@ -0,0 +1,368 @@
+//go:build !(js && wasm)
+
+// Command routegen writes the RegisterRoutes function of the app package from
+// its directory tree. It is meant to be run by go generate from the app
+// directory:
+//
+//	//go:generate go run github.com/RafaelCoppe/Stencil-Framework/core/cmd/routegen
+//
+// Every directory is a route segment. A directory with a page.go, create.go or
+// edit.go file is registered with RegisterPageRoute and a layout.go file with
+// RegisterLayout. The file must declare a single exported type with a Render
+// method: Render() string for pages, Render(ctx, slot) string for layouts.
+//
+// Go import paths cannot contain brackets, so parameter directories use
+// underscores: _id for :id, _id_ for :id?, __slug for *slug and __slug_ for
+// *slug?. The output only depends on the files, directories are visited in
+// lexical order.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const frameworkImport = "github.com/RafaelCoppe/Stencil-Framework/core/framework"
+
+// routeDir is a directory of the app tree with pages or a layout
+type routeDir struct {
+	route      string // route path, such as /users/:id
+	importPath string // empty for the app package itself
+	pkgName    string
+	alias      string // name the package is referred to with in the generated file
+
+	// Type names, empty when the file is missing
+	page, create, edit, layout string
+}
+
+func main() {
+	dir := flag.String("dir", ".", "app directory to scan")
+	out := flag.String("out", "routes_gen.go", "generated file, relative to the app directory")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("routegen: ")
+
+	dirs, err := scanApp(*dir, *out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(dirs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// scanApp walks the app directory and returns its route directories, the app package first
+func scanApp(root, out string) ([]*routeDir, error) {
+	modulePath, moduleRoot, err := findModule(root)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []*routeDir
+	err = filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(absRoot, path)
+		name := d.Name()
+		if path != absRoot && (strings.HasPrefix(name, ".") || name == "testdata") {
+			return filepath.SkipDir
+		}
+		if strings.ContainsAny(name, "[]") {
+			return fmt.Errorf("%s: Go import paths cannot contain brackets, name the directory %s instead", filepath.Join(root, rel), bracketsToUnderscores(name))
+		}
+
+		dir := &routeDir{route: "/"}
+		if path != absRoot {
+			var segments []string
+			for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+				segment, err := routeSegment(part)
+				if err != nil {
+					return fmt.Errorf("%s: %v", filepath.Join(root, rel), err)
+				}
+				segments = append(segments, segment)
+			}
+			dir.route = "/" + strings.Join(segments, "/")
+			moduleRel, _ := filepath.Rel(moduleRoot, path)
+			dir.importPath = modulePath + "/" + filepath.ToSlash(moduleRel)
+		}
+
+		skip := ""
+		if path == absRoot {
+			skip = out
+		}
+		found, err := scanPackage(path, skip, dir)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filepath.Join(root, rel), err)
+		}
+		if found || path == absRoot {
+			dirs = append(dirs, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	assignAliases(dirs[1:])
+	return dirs, nil
+}
+
+// routeSegment returns the route segment of a directory name
+func routeSegment(name string) (string, error) {
+	segment := name
+	switch {
+	case strings.HasPrefix(name, "__") && strings.HasSuffix(name, "_") && len(name) > 3:
+		segment = "*" + name[2:len(name)-1] + "?"
+	case strings.HasPrefix(name, "__"):
+		segment = "*" + name[2:]
+	case strings.HasPrefix(name, "_") && strings.HasSuffix(name, "_") && len(name) > 2:
+		segment = ":" + name[1:len(name)-1] + "?"
+	case strings.HasPrefix(name, "_"):
+		segment = ":" + name[1:]
+	}
+	if strings.Trim(segment, ":*?") == "" {
+		return "", fmt.Errorf("parameter directory %q has no name", name)
+	}
+	return segment, nil
+}
+
+// bracketsToUnderscores gives the directory name to use for [id], [[id]], [...slug] and [[...slug]]
+func bracketsToUnderscores(name string) string {
+	optional := strings.HasPrefix(name, "[[") && strings.HasSuffix(name, "]]")
+	name = strings.Trim(name, "[]")
+	prefix := "_"
+	if strings.HasPrefix(name, "...") {
+		prefix, name = "__", name[3:]
+	}
+	if optional {
+		return prefix + name + "_"
+	}
+	return prefix + name
+}
+
+// scanPackage reads the page, create, edit and layout types of the package in path.
+// It reports whether the directory has any of them.
+func scanPackage(path, skip string, dir *routeDir) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	files := make(map[string]*ast.File)
+	renders := make(map[string]int) // parameter count of the Render method of each type
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == skip {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(path, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return false, err
+		}
+		files[name] = file
+		dir.pkgName = file.Name.Name
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && fn.Name.Name == "Render" {
+				renders[receiverType(fn.Recv.List[0].Type)] = fieldCount(fn.Type.Params)
+			}
+		}
+	}
+
+	targets := []struct {
+		file   string
+		params int
+		kind   string
+		name   *string
+	}{
+		{"page.go", 0, "page", &dir.page},
+		{"create.go", 0, "page", &dir.create},
+		{"edit.go", 0, "page", &dir.edit},
+		{"layout.go", 2, "layout", &dir.layout},
+	}
+	found := false
+	for _, target := range targets {
+		file, exists := files[target.file]
+		if !exists {
+			continue
+		}
+		types := renderTypes(file, renders, target.params)
+		if len(types) != 1 {
+			return false, fmt.Errorf("%s must declare one exported %s type with a Render method, found %d %v", target.file, target.kind, len(types), types)
+		}
+		*target.name = types[0]
+		found = true
+	}
+	return found, nil
+}
+
+// renderTypes returns the exported types declared in file whose Render method takes params parameters
+func renderTypes(file *ast.File, renders map[string]int, params int) []string {
+	var types []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			name := spec.(*ast.TypeSpec).Name.Name
+			if count, exists := renders[name]; exists && count == params && ast.IsExported(name) {
+				types = append(types, name)
+			}
+		}
+	}
+	return types
+}
+
+// receiverType returns the type name of a method receiver, T or *T
+func receiverType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// fieldCount returns the number of parameters of a parameter list
+func fieldCount(fields *ast.FieldList) int {
+	count := 0
+	for _, field := range fields.List {
+		count += max(len(field.Names), 1)
+	}
+	return count
+}
+
+// assignAliases names the imported packages, packages sharing a name are
+// referred to by their directory path instead, such as usersid for users/_id
+func assignAliases(dirs []*routeDir) {
+	uses := map[string]int{"framework": 1}
+	for _, dir := range dirs {
+		uses[dir.pkgName]++
+	}
+	taken := make(map[string]bool)
+	for _, dir := range dirs {
+		if uses[dir.pkgName] == 1 {
+			dir.alias = dir.pkgName
+			taken[dir.alias] = true
+		}
+	}
+	for _, dir := range dirs {
+		if dir.alias != "" {
+			continue
+		}
+		base := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+				return r
+			}
+			if r >= 'A' && r <= 'Z' {
+				return r + 'a' - 'A'
+			}
+			return -1
+		}, dir.route)
+		alias := base
+		for i := 2; taken[alias] || uses[alias] > 0; i++ {
+			alias = fmt.Sprintf("%s%d", base, i)
+		}
+		dir.alias = alias
+		taken[alias] = true
+	}
+}
+
+// generate returns the source of the generated file
+func generate(dirs []*routeDir) ([]byte, error) {
+	if dirs[0].pkgName == "" {
+		return nil, fmt.Errorf("the app directory has no Go package")
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by routegen from the app directory. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", dirs[0].pkgName)
+
+	imports := []string{frameworkImport}
+	aliases := map[string]string{frameworkImport: ""}
+	for _, dir := range dirs[1:] {
+		imports = append(imports, dir.importPath)
+		if dir.alias != dir.pkgName || filepath.Base(dir.importPath) != dir.pkgName {
+			aliases[dir.importPath] = dir.alias + " "
+		}
+	}
+	sort.Strings(imports)
+	b.WriteString("import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&b, "\t%s%q\n", aliases[path], path)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// RegisterRoutes registers the pages and layouts of the app directory\n")
+	b.WriteString("func RegisterRoutes() {\n")
+	for _, dir := range dirs {
+		qualifier := ""
+		if dir.importPath != "" {
+			qualifier = dir.alias + "."
+		}
+		if dir.layout != "" {
+			fmt.Fprintf(&b, "\tframework.RegisterLayout(%q, func() framework.Layout { return &%s%s{} })\n", dir.route, qualifier, dir.layout)
+		}
+		if dir.page == "" && dir.create == "" && dir.edit == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\tframework.RegisterPageRoute(%q,\n", dir.route)
+		for _, name := range []string{dir.page, dir.create, dir.edit} {
+			if name == "" {
+				b.WriteString("\t\tnil,\n")
+				continue
+			}
+			fmt.Fprintf(&b, "\t\tfunc() framework.PageInterface { return &%s%s{} },\n", qualifier, name)
+		}
+		b.WriteString("\t)\n")
+	}
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
+
+// findModule returns the module path and root directory of the module holding dir
+func findModule(dir string) (modulePath, moduleRoot string, err error) {
+	moduleRoot, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(moduleRoot, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+					return strings.Trim(fields[1], `"`), moduleRoot, nil
+				}
+			}
+			return "", "", fmt.Errorf("%s has no module line", filepath.Join(moduleRoot, "go.mod"))
+		}
+		parent := filepath.Dir(moduleRoot)
+		if parent == moduleRoot {
+			return "", "", fmt.Errorf("no go.mod above %s", dir)
+		}
+		moduleRoot = parent
+	}
+}
@@ -1,245 +1,160 @@
-//go:build js && wasm
-
 package framework
 
 import (
-	"fmt"
-	"syscall/js"
-)
+	"net/url"
+	"sync/atomic"
 
-// PageInterface represents the interface that user pages must implement
-type PageInterface interface {
-	Render() string
-	HandleEvent(eventName string, event js.Value)
-	GetInitialState() map[string]interface{}
-}
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
 
 // App represents the main application framework (internal use only)
 type app struct {
-	container js.Value
-	state     map[string]interface{}
-	page      PageInterface
-}
+	platform // browser specific tooling, see app_js.go
 
-// Global app instance
-var appInstance *app
-
-// newApp creates a new application instance (internal)
-func newApp(containerId string) *app {
-	container := js.Global().Get("document").Call("getElementById", containerId)
-	if container.IsNull() {
-		panic(fmt.Sprintf("Element with ID '%s' not found", containerId))
-	}
+	host          host.Host
+	container     host.Container // nil for server renders, see ssr.go
+	html          string         // output of the last render
+	stopListening func()
 
-	return &app{
-		container: container,
-		state:     make(map[string]interface{}),
-	}
-}
+	state      *store
+	page       PageInterface
+	mounted    bool // whether the page went through its first render
+	components map[string]*componentInstance
+	layouts    []*layoutInstance // layouts around the page, outermost first, see layout.go
+	hydration  *hydration        // pre-rendered state the first page resumes from, see hydrate.go
+	failure    *ErrorInfo        // panic that replaced the page with the error view, see boundary.go
+	params     map[string]string // parameters of the current route, see route.go
+	query      url.Values        // query of the current location, see query.go
 
-// SetRootComponent sets the root component to render
-func (a *app) setPage(page PageInterface) {
-	a.page = page
-}
+	prerenderPath string // path of a server render, which does not go through the router
 
-// setState updates the application state
-func (a *app) setState(key string, value interface{}) {
-	a.state[key] = value
+	// Render scheduling, see scheduler.go. The flags are set by writes from
+	// any goroutine, the batch depth belongs to the render loop.
+	dirty          atomic.Bool
+	frameRequested atomic.Bool
+	batchDepth     int
 }
 
-// getState retrieves a value from the state
-func (a *app) getState(key string) interface{} {
-	return a.state[key]
-}
+// Global app instance
+var appInstance *app
 
-// update re-renders the application
-func (a *app) update() {
-	if a.page != nil {
-		html := a.page.Render()
-		a.render(html)
+// newAppCore creates an application instance without any DOM binding
+func newAppCore() *app {
+	a := &app{
+		state:      newStore(),
+		components: make(map[string]*componentInstance),
 	}
+	// Writes may come from goroutines, the render loop picks them up on the next frame
+	a.state.notify = a.invalidate
+	a.state.seed(appScope, "", loadPersisted(appScope))
+	return a
 }
 
-// render updates the DOM with the generated HTML
-func (a *app) render(html string) {
-	a.container.Set("innerHTML", html)
-	a.attachEventListeners()
-}
-
-// attachEventListeners attaches event listeners after rendering
-func (a *app) attachEventListeners() {
-	// Attach click events for buttons with data-onclick
-	buttons := a.container.Call("querySelectorAll", "[data-onclick]")
-	for i := 0; i < buttons.Length(); i++ {
-		button := buttons.Index(i)
-		onclick := button.Get("dataset").Get("onclick").String()
-
-		eventFunc := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			a.handleEvent(onclick, args[0])
-			return nil
-		})
-
-		button.Call("addEventListener", "click", eventFunc)
-	}
-
-	// Attach change events for inputs
-	inputs := a.container.Call("querySelectorAll", "input[data-onchange]")
-	for i := 0; i < inputs.Length(); i++ {
-		input := inputs.Index(i)
-		onchange := input.Get("dataset").Get("onchange").String()
-
-		eventFunc := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			a.handleEvent(onchange, args[0])
-			return nil
-		})
-
-		input.Call("addEventListener", "change", eventFunc)
+// newApp creates an application rendering into the container with the given id of h.
+// The global router follows the location and history of h.
+func newApp(h host.Host, containerId string) (*app, error) {
+	container, err := h.Container(containerId)
+	if err != nil {
+		return nil, err
 	}
 
-	// Attach click events for router navigation links
-	links := a.container.Call("querySelectorAll", "a[href^='/']")
-	for i := 0; i < links.Length(); i++ {
-		link := links.Index(i)
-
-		eventFunc := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			event := args[0]
-			event.Call("preventDefault")
-
-			href := link.Get("href").String()
-			// Extract path from full URL
-			url := js.Global().Get("URL").New(href)
-			path := url.Get("pathname").String()
-
-			// Navigate using router
-			if globalRouter != nil {
-				globalRouter.Navigate(path)
-			}
-			return nil
-		})
-
-		link.Call("addEventListener", "click", eventFunc)
-	}
-}
-
-// handleEvent handles custom events by delegating to the user's page
-func (a *app) handleEvent(eventName string, event js.Value) {
-	if a.page != nil {
-		a.page.HandleEvent(eventName, event)
-		a.update() // Auto re-render after event handling
-	}
+	a := newAppCore()
+	a.host = h
+	a.container = container
+	a.stopListening = container.Listen(a.dispatchDOMEvent)
+	InitRouter().attach(h)
+	return a, nil
 }
 
-// start initializes and starts the application
-func (a *app) start() {
-	// Initialize state with user's initial state
-	if a.page != nil {
-		initialState := a.page.GetInitialState()
-		for key, value := range initialState {
-			a.state[key] = value
+// render updates the container with the generated HTML
+func (a *app) render(html string) {
+	a.html = html
+	if a.container == nil {
+		return
+	}
+	if a.hydration != nil {
+		// The pre-rendered markup is adopted as is, unless the page renders something else
+		if mismatch := a.container.Adopt(html); mismatch != "" {
+			warn("stencil: the pre-rendered page does not match the render, patching it (%s)", mismatch)
 		}
-	}
-
-	a.update()
-	// Keep the program alive
-	select {}
-}
-
-// startWithRouter starts the application in router mode
-func (a *app) startWithRouter() {
-	// Let the router handle the initial render
-	if globalRouter != nil {
-		// Force immediate render for the current path
-		globalRouter.currentPath = js.Global().Get("location").Get("pathname").String()
-		globalRouter.render()
-	}
-
-	// Keep the program alive
-	select {}
-}
-
-// Public API for users
-
-// Run starts the Stencil application with the provided page
-// This is the main entry point for users
-func Run(page PageInterface, containerId ...string) {
-	containerID := "app" // default
-	if len(containerId) > 0 {
-		containerID = containerId[0]
-	}
-
-	appInstance = newApp(containerID)
-
-	// Initialize router
-	router := InitRouter()
-
-	// If a page is provided, register it as the default route
+		a.hydration = nil
+	} else {
+		a.container.Render(html)
+	}
+	a.syncBindings()
+}
+
+// mount makes page the current page.
+// The previous page is unmounted and its state and watchers disposed of, the
+// page-scoped state is initialised from the new page's GetInitialState and
+// from the persisted page keys. The layouts it shares with the previous page
+// are kept.
+func (a *app) mount(page PageInterface) {
+	a.unmountPage()
+	stopPageWatchers()
+	clearHistory()
+	a.mountLayouts(renderedPath())
+	a.page = page
+	a.failure = nil
+	a.components = make(map[string]*componentInstance)
+	var initial map[string]interface{}
 	if page != nil {
-		router.RegisterRoute("/", func() PageInterface { return page })
-	}
-
-	// Start the application
-	appInstance.page = page
-	appInstance.start()
-}
-
-// RunWithRouter starts the framework with routing capabilities
-func RunWithRouter(containerId ...string) {
-	containerID := "app" // default
-	if len(containerId) > 0 {
-		containerID = containerId[0]
+		a.failure = protect(ErrorInfo{Phase: "mount"}, func() {
+			initial = page.GetInitialState()
+		})
 	}
-
-	appInstance = newApp(containerID)
-
-	// Initialize router
-	InitRouter()
-
-	// Start the application in router mode
-	appInstance.startWithRouter()
+	a.state.resetPage(a.hydration.pageState(initial))
+	a.state.seed(pageScope, "", loadPersisted(pageScope))
 }
 
-// SetState updates the application state (available to user pages)
-func SetState(key string, value interface{}) {
-	if appInstance != nil {
-		appInstance.setState(key, value)
-		appInstance.update()
+// update re-renders the application synchronously
+func (a *app) update() {
+	// Cleared before the commit: a write queued meanwhile marks the app dirty again
+	a.dirty.Store(false)
+	a.commit()
+	if a.page != nil {
+		a.render(a.renderPage())
+		a.afterRender()
 	}
+	a.renderHistoryOverlay()
 }
 
-// GetState retrieves a value from the application state (available to user pages)
-func GetState(key string) interface{} {
-	if appInstance != nil {
-		return appInstance.getState(key)
+// renderPage renders the current page, or the error view once the page failed, inside its layouts
+func (a *app) renderPage() string {
+	var html string
+	if a.failure == nil {
+		a.failure = protect(ErrorInfo{Phase: "render"}, func() {
+			html = a.page.Render()
+		})
 	}
-	return nil
-}
-
-// GetStateString retrieves a state value as string
-func GetStateString(key string) string {
-	if val := GetState(key); val != nil {
-		if str, ok := val.(string); ok {
-			return str
-		}
+	if a.failure != nil {
+		return a.renderLayouts(renderErrorView(*a.failure))
 	}
-	return ""
+	html = a.renderLayouts(html)
+	a.sweepComponents()
+	return html
 }
 
-// GetStateInt retrieves a state value as int
-func GetStateInt(key string) int {
-	if val := GetState(key); val != nil {
-		if i, ok := val.(int); ok {
-			return i
-		}
-	}
-	return 0
+// commit applies the queued state writes and runs what depends on them
+func (a *app) commit() {
+	committed := a.state.commit()
+	savePersisted(committed)
+	recordHistory(committed)
+	notifyWatchers(committed)
 }
 
-// GetStateBool retrieves a state value as bool
-func GetStateBool(key string) bool {
-	if val := GetState(key); val != nil {
-		if b, ok := val.(bool); ok {
-			return b
-		}
+// handleEvent handles custom events by delegating to the user's page
+func (a *app) handleEvent(eventName string, event Event) {
+	// A failed page only shows the error view, its handlers are not reachable anymore
+	if a.page != nil && a.failure == nil {
+		// Every SetState made by the handler is coalesced with the auto re-render
+		a.batch(func() {
+			a.state.setCause(eventName)
+			defer a.state.setCause("")
+			a.failure = protect(ErrorInfo{Phase: "event", Event: eventName}, func() {
+				a.page.HandleEvent(eventName, event)
+			})
+			a.invalidate()
+		})
 	}
-	return false
 }
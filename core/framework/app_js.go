@@ -0,0 +1,103 @@
+//go:build js && wasm
+
+package framework
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// platform holds the browser tooling of the app
+type platform struct {
+	historyFunc js.Func // listener of the time-travel overlay, see history_js.go
+}
+
+// start initializes and starts the application
+func (a *app) start(page PageInterface) {
+	// The router may already have mounted the page on initial load
+	if a.page != page {
+		a.mount(page)
+	}
+
+	a.update()
+	// Keep the program alive
+	select {}
+}
+
+// startWithRouter starts the application in router mode
+func (a *app) startWithRouter() {
+	// Let the router handle the initial render
+	if globalRouter != nil {
+		// Force immediate render for the current location
+		location := a.host.Location()
+		globalRouter.currentPath, globalRouter.currentQuery = splitLocation(location)
+		// A pre-rendered page resumes from its serialized state instead of GetInitialState,
+		// if it was rendered for the same path and query
+		if h := readHydration(); h != nil {
+			path, rawQuery := splitLocation(h.Path)
+			if samePath(path, globalRouter.currentPath) && rawQuery == globalRouter.currentQuery {
+				a.hydration = h
+			}
+		}
+		globalRouter.render()
+	}
+
+	// Keep the program alive
+	select {}
+}
+
+// mustNewApp creates the application in the browser, the container must exist
+func mustNewApp(containerId string) *app {
+	a, err := newApp(BrowserHost(), containerId)
+	if err != nil {
+		panic(err.Error())
+	}
+	return a
+}
+
+// Public API for users
+
+// Run starts the Stencil application with the provided page
+// This is the main entry point for users
+func Run(page PageInterface, containerId ...string) {
+	containerID := "app" // default
+	if len(containerId) > 0 {
+		containerID = containerId[0]
+	}
+
+	appInstance = mustNewApp(containerID)
+
+	// Initialize router
+	router := InitRouter()
+
+	// If a page is provided, it is rendered for every path the application has no route for
+	if page != nil {
+		router.RegisterRoute("/*?", func() PageInterface { return page })
+	}
+
+	// Start the application
+	appInstance.start(page)
+}
+
+// RunWithRouter starts the framework with routing capabilities
+func RunWithRouter(containerId ...string) {
+	containerID := "app" // default
+	if len(containerId) > 0 {
+		containerID = containerId[0]
+	}
+
+	appInstance = mustNewApp(containerID)
+
+	// Start the application in router mode
+	appInstance.startWithRouter()
+}
+
+// warn logs a warning to the browser console
+func warn(format string, args ...interface{}) {
+	js.Global().Get("console").Call("warn", fmt.Sprintf(format, args...))
+}
+
+// logError logs an error to the browser console
+func logError(format string, args ...interface{}) {
+	js.Global().Get("console").Call("error", fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,18 @@
+//go:build !(js && wasm)
+
+package framework
+
+import "log"
+
+// platform holds no browser tooling outside the browser
+type platform struct{}
+
+// warn logs a warning
+func warn(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+// logError logs an error
+func logError(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
@@ -0,0 +1,210 @@
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// bindAttr binds a form control to a state key: data-bind="email"
+const bindAttr = "data-bind"
+
+// parseBoundValue converts the raw value of a form control into a state value.
+// The type of the current state value wins, so an int stays an int and a
+// float64 stays a float64. Unset keys bound to number or range inputs become
+// an int (or a float64 for decimals), every other control stores a string.
+// ok is false when the raw value cannot be converted, for example while the
+// user is still typing a number.
+func parseBoundValue(current interface{}, raw string, inputType string) (value interface{}, ok bool) {
+	if current == nil {
+		if inputType != "number" && inputType != "range" {
+			return raw, true
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, false
+		}
+		if i, ok := convertNumber(f, reflect.TypeOf(0)); ok {
+			return i.Interface(), true
+		}
+		return f, true
+	}
+
+	switch current.(type) {
+	case string:
+		return raw, true
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		return b, err == nil
+	}
+
+	want := reflect.TypeOf(current)
+	if !isNumericKind(want.Kind()) {
+		// Other types cannot be edited through a text control
+		return nil, false
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, false
+	}
+	converted, ok := convertNumber(f, want)
+	if !ok {
+		return nil, false
+	}
+	return converted.Interface(), true
+}
+
+// formatBoundValue converts a state value into the value of a form control
+func formatBoundValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toggleBoundValue adds or removes option from a checkbox group bound to a []string
+func toggleBoundValue(current []string, option string, checked bool) []string {
+	next := make([]string, 0, len(current)+1)
+	for _, v := range current {
+		if v != option {
+			next = append(next, v)
+		}
+	}
+	if checked {
+		next = append(next, option)
+	}
+	return next
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// bindingKey returns the state key of a bound control.
+// Controls rendered by a component are bound to the component's local state.
+func (a *app) bindingKey(el host.Element) string {
+	key, _ := el.Attr(bindAttr)
+	if component := el.Closest("[" + componentAttr + "]"); component != nil && a.container.Contains(component) {
+		id, _ := component.Attr(componentAttr)
+		return ComponentKey(id, key)
+	}
+	return key
+}
+
+// applyBinding copies the value of a bound form control into the state
+func (a *app) applyBinding(el host.Element) {
+	if _, bound := el.Attr(bindAttr); !bound || !a.container.Contains(el) {
+		return
+	}
+
+	key := a.bindingKey(el)
+	current, _ := lookupState(pageScope, key)
+	tag := el.Tag()
+	inputType := controlType(el)
+
+	switch {
+	case tag == "input" && inputType == "checkbox":
+		// A checkbox bound to a []string is one option of a group
+		if list, ok := current.([]string); ok {
+			setScopedState(pageScope, key, toggleBoundValue(list, el.Value(), el.Checked()))
+			return
+		}
+		setScopedState(pageScope, key, el.Checked())
+
+	case tag == "input" && inputType == "radio":
+		if el.Checked() {
+			if value, ok := parseBoundValue(current, el.Value(), inputType); ok {
+				setScopedState(pageScope, key, value)
+			}
+		}
+
+	case tag == "select" && isMultiple(el):
+		setScopedState(pageScope, key, el.Selected())
+
+	default:
+		if value, ok := parseBoundValue(current, el.Value(), inputType); ok {
+			setScopedState(pageScope, key, value)
+		}
+	}
+}
+
+// syncBindings writes the bound state values into the form controls after a render.
+// Properties are only written when they differ, so the caret of the control
+// being edited is never moved.
+func (a *app) syncBindings() {
+	focused := a.container.Focused()
+	for _, el := range a.container.Query("[" + bindAttr + "]") {
+		value, exists := lookupState(pageScope, a.bindingKey(el))
+		if !exists {
+			continue
+		}
+
+		tag := el.Tag()
+		inputType := controlType(el)
+
+		switch {
+		case tag == "input" && inputType == "checkbox":
+			checked := false
+			if list, ok := value.([]string); ok {
+				checked = containsString(list, el.Value())
+			} else if b, ok := value.(bool); ok {
+				checked = b
+			}
+			if el.Checked() != checked {
+				el.SetChecked(checked)
+			}
+
+		case tag == "input" && inputType == "radio":
+			checked := el.Value() == formatBoundValue(value)
+			if el.Checked() != checked {
+				el.SetChecked(checked)
+			}
+
+		case tag == "select" && isMultiple(el):
+			list, _ := value.([]string)
+			el.SetSelected(list)
+
+		default:
+			// A control whose text already reads as the state value is left alone,
+			// so typing "1." into a number bound to a float64 is not undone, and
+			// neither is the focused control while its text is not a valid value yet
+			parsed, ok := parseBoundValue(value, el.Value(), inputType)
+			if ok && reflect.DeepEqual(parsed, value) || !ok && focused != nil && el.Same(focused) {
+				continue
+			}
+			formatted := formatBoundValue(value)
+			if el.Value() != formatted {
+				el.SetValue(formatted)
+			}
+		}
+	}
+}
+
+// controlType returns the lowercase type attribute of a form control
+func controlType(el host.Element) string {
+	t, _ := el.Attr("type")
+	return strings.ToLower(t)
+}
+
+// isMultiple reports whether a select accepts several options
+func isMultiple(el host.Element) bool {
+	_, multiple := el.Attr("multiple")
+	return multiple
+}
@@ -0,0 +1,199 @@
+package framework
+
+import (
+	"fmt"
+	"html"
+	"runtime/debug"
+)
+
+// ErrorInfo describes a panic recovered by an error boundary.
+// Boundaries surround the route handlers, the Render, HandleEvent and
+// lifecycle hooks of pages and layouts and the Render and HandleEvent of
+// components: instead of taking down the wasm runtime, a panic replaces the
+// page, the layout or only the component with the error view and is sent to
+// the error reporter. A failed page is still shown inside its layouts.
+// The router keeps working, so the user can navigate away.
+//
+// Panics in goroutines started by pages cannot be recovered by the framework,
+// unless the goroutine runs its work through Protect.
+type ErrorInfo struct {
+	// Err is the panic value, wrapped in an error when it is not one
+	Err error
+	// Stack is the stack trace of the panic
+	Stack string
+	// Phase is where the panic happened: "route", "render", "event", "mount", "update", "unmount", "leave"
+	// or "watch", or the phase given to Protect, such as "submit" and "validate" for forms
+	Phase string
+	// Path is the route path when the panic happened
+	Path string
+	// Component is the id of the component instance, empty for pages
+	Component string
+	// Layout is the path the failed layout was registered for, empty for pages
+	Layout string
+	// Event is the event name for the "event" phase
+	Event string
+	// Key is the state key of the watcher for the "watch" phase
+	Key string
+}
+
+// ErrorView renders the HTML shown in place of a failed page, layout or component
+type ErrorView func(info ErrorInfo) string
+
+// ErrorReporter receives the panics recovered by the error boundaries, for
+// example to send them to an error tracking service
+type ErrorReporter func(info ErrorInfo)
+
+var (
+	errorView     ErrorView
+	errorReporter ErrorReporter
+)
+
+// SetErrorView replaces the view rendered in place of a failed page, layout
+// or component. The info.Component and info.Layout fields tell them apart.
+// nil restores the default view.
+func SetErrorView(view ErrorView) {
+	errorView = view
+}
+
+// SetErrorReporter replaces the reporter of the recovered panics, which logs
+// them with their stack trace by default. nil restores the default reporter.
+//
+//	framework.SetErrorReporter(func(info framework.ErrorInfo) {
+//		http.POST("/errors", map[string]string{"error": info.Err.Error(), "stack": info.Stack})
+//	})
+func SetErrorReporter(reporter ErrorReporter) {
+	errorReporter = reporter
+}
+
+// protect runs fn behind an error boundary. A panic is reported and returned
+// as an ErrorInfo built from info, nil is returned when fn completes.
+func protect(info ErrorInfo, fn func()) *ErrorInfo {
+	return recoverPanic(info, fn, renderedPath)
+}
+
+// Protect runs fn, in a goroutine started by a page or a component, behind
+// an error boundary: a panic is sent to the error reporter instead of taking
+// down the wasm runtime, and returned as an ErrorInfo built from info. It
+// returns nil when fn completes. The route is not read off the render loop,
+// set info.Path before starting the goroutine if the reporter needs it.
+//
+//	go func() {
+//		failure := framework.Protect(framework.ErrorInfo{Phase: "load"}, load)
+//		if failure != nil {
+//			framework.SetState("error", failure.Err.Error())
+//		}
+//	}()
+func Protect(info ErrorInfo, fn func()) *ErrorInfo {
+	return recoverPanic(info, fn, nil)
+}
+
+// recoverPanic runs fn and turns a panic into a reported ErrorInfo.
+// path, when set, fills in an empty info.Path.
+func recoverPanic(info ErrorInfo, fn func(), path func() string) (failure *ErrorInfo) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		err, ok := recovered.(error)
+		if !ok {
+			err = fmt.Errorf("%v", recovered)
+		}
+		info.Err = err
+		info.Stack = string(debug.Stack())
+		if info.Path == "" && path != nil {
+			info.Path = path()
+		}
+		reportError(info)
+		failure = &info
+	}()
+	fn()
+	return nil
+}
+
+// renderedPath returns the path of the page being rendered
+func renderedPath() string {
+	if appInstance != nil && appInstance.prerenderPath != "" {
+		return appInstance.prerenderPath
+	}
+	if globalRouter != nil {
+		return globalRouter.currentPath
+	}
+	return ""
+}
+
+// reportError sends info to the error reporter
+func reportError(info ErrorInfo) {
+	if errorReporter == nil {
+		logError("stencil: panic during %s: %v\n%s", describeFailure(info), info.Err, info.Stack)
+		return
+	}
+	// A failing reporter must not take the application down either
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logError("stencil: the error reporter panicked: %v", recovered)
+		}
+	}()
+	errorReporter(info)
+}
+
+// renderErrorView renders the error view for info, falling back to the
+// default view when a custom view panics
+func renderErrorView(info ErrorInfo) (view string) {
+	if errorView != nil {
+		failure := protect(ErrorInfo{Phase: "render", Component: info.Component}, func() {
+			view = errorView(info)
+		})
+		if failure == nil {
+			return view
+		}
+	}
+	return defaultErrorView(info)
+}
+
+// defaultErrorView shows the error message, with a link to the home page when the whole page failed
+func defaultErrorView(info ErrorInfo) string {
+	message := html.EscapeString(info.Err.Error())
+	if info.Component != "" {
+		return `<div class="alert alert-danger" role="alert">` + message + `</div>`
+	}
+	return `
+	<div class="container mt-5">
+		<div class="alert alert-danger" role="alert">
+			<h4 class="alert-heading">Something went wrong</h4>
+			<p class="mb-0">` + message + `</p>
+		</div>
+		<a href="/" class="btn btn-primary">Go Home</a>
+	</div>`
+}
+
+// describeFailure names where a panic happened, for the logs
+func describeFailure(info ErrorInfo) string {
+	where := info.Phase
+	if info.Event != "" {
+		where += " of " + info.Event
+	}
+	if info.Key != "" {
+		where += " of key " + info.Key
+	}
+	if info.Component != "" {
+		where += " in component " + info.Component
+	}
+	if info.Layout != "" {
+		where += " in layout " + info.Layout
+	}
+	if info.Path != "" {
+		where += " on " + info.Path
+	}
+	return where
+}
+
+// errorPage stands in for a page whose route handler panicked
+type errorPage struct {
+	BasePage
+	info ErrorInfo
+}
+
+func (p *errorPage) Render() string {
+	return renderErrorView(p.info)
+}
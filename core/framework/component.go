@@ -1,27 +1,203 @@
-//go:build js && wasm
-
 package framework
 
 import (
-	"syscall/js"
-
-	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
+	"html"
+	"strings"
 )
 
-// BasePage provides a base implementation that users can embed in their pages
-type BasePage struct{}
+// componentAttr marks the root element of an embedded component instance
+const componentAttr = "data-stencil-component"
+
+// componentKeyPrefix starts the page state keys of component instances.
+// The NUL bytes around the instance id cannot appear in the keys a page
+// uses, so instances never share keys with the page or with each other,
+// whatever their ids.
+const componentKeyPrefix = "\x00cmp:"
+
+// Props represents the properties passed to a component by its parent
+type Props map[string]interface{}
+
+// Component is a reusable widget with its own local state and event handler.
+// The same component can be embedded several times in a page: every instance
+// has its own state and receives only the events fired inside its own markup.
+type Component interface {
+	Render(c *ComponentContext) string
+	HandleEvent(c *ComponentContext, eventName string, event Event)
+	GetInitialState(props Props) map[string]interface{}
+}
+
+// BaseComponent provides a base implementation that users can embed in their components
+type BaseComponent struct{}
 
 // GetInitialState provides default empty initial state
-func (p *BasePage) GetInitialState() map[string]interface{} {
+func (c *BaseComponent) GetInitialState(props Props) map[string]interface{} {
 	return make(map[string]interface{})
 }
 
 // HandleEvent provides default empty event handling
-func (p *BasePage) HandleEvent(eventName string, event js.Value) {
-	// Override this method in your page to handle events
+func (c *BaseComponent) HandleEvent(ctx *ComponentContext, eventName string, event Event) {
+	// Override this method in your component to handle events
+}
+
+// ComponentContext gives a component instance access to its props and local state
+type ComponentContext struct {
+	id    string
+	Props Props
+}
+
+// componentInstance is a component embedded in the current page
+type componentInstance struct {
+	component Component
+	ctx       *ComponentContext
+	rendered  bool       // embedded during the current render
+	failure   *ErrorInfo // panic that replaced the instance with the error view
+}
+
+// ID returns the instance id given to Embed
+func (c *ComponentContext) ID() string {
+	return c.id
+}
+
+// Key returns the page state key backing a local state key,
+// it can be used with the typed state API: framework.NewKey[int](c.Key("count"))
+func (c *ComponentContext) Key(key string) string {
+	return ComponentKey(c.id, key)
+}
+
+// ComponentKey returns the page state key backing the local state key of the
+// instance embedded as id. It lets a page read the state of its components.
+func ComponentKey(id, key string) string {
+	return componentPrefix(id) + key
+}
+
+// componentPrefix returns the prefix of the page state keys of the instance embedded as id
+func componentPrefix(id string) string {
+	return componentKeyPrefix + id + "\x00"
+}
+
+// keyLabel returns a state key as shown to developers, id.key for the keys of components and layouts
+func keyLabel(key string) string {
+	for _, prefix := range []string{componentKeyPrefix, layoutKeyPrefix} {
+		if rest, ok := strings.CutPrefix(key, prefix); ok {
+			if id, local, found := strings.Cut(rest, "\x00"); found {
+				return id + "." + local
+			}
+		}
+	}
+	return key
+}
+
+// Get retrieves a value from the local state
+func (c *ComponentContext) Get(key string) interface{} {
+	value, _ := lookupState(pageScope, c.Key(key))
+	return value
+}
+
+// GetString retrieves a local state value as string
+func (c *ComponentContext) GetString(key string) string {
+	value, _ := Lookup(NewKey[string](c.Key(key)))
+	return value
+}
+
+// GetInt retrieves a local state value as int
+func (c *ComponentContext) GetInt(key string) int {
+	value, _ := Lookup(NewKey[int](c.Key(key)))
+	return value
+}
+
+// GetBool retrieves a local state value as bool
+func (c *ComponentContext) GetBool(key string) bool {
+	value, _ := Lookup(NewKey[bool](c.Key(key)))
+	return value
+}
+
+// Set updates the local state and schedules a re-render
+func (c *ComponentContext) Set(key string, value interface{}) {
+	setScopedState(pageScope, c.Key(key), value)
+}
+
+// Prop retrieves a prop as string with a default value
+func (c *ComponentContext) Prop(key string, defaultValue string) string {
+	if str, ok := c.Props[key].(string); ok {
+		return str
+	}
+	return defaultValue
+}
+
+// Emit forwards an event to the page as "<id>:<eventName>"
+func (c *ComponentContext) Emit(eventName string, event Event) {
+	if appInstance != nil && appInstance.page != nil {
+		appInstance.page.HandleEvent(c.id+":"+eventName, event)
+	}
+}
+
+// Embed renders a component instance identified by id.
+// It is meant to be called from a page's Render method. The instance and its
+// state are created the first time an id is embedded and kept for as long as
+// the page keeps embedding it; later calls only refresh the props.
+func Embed(id string, component Component, props Props) string {
+	if appInstance == nil {
+		return ""
+	}
+	if props == nil {
+		props = Props{}
+	}
+
+	instance, exists := appInstance.components[id]
+	if !exists {
+		instance = &componentInstance{
+			component: component,
+			ctx:       &ComponentContext{id: id},
+		}
+		appInstance.components[id] = instance
+		var initial map[string]interface{}
+		instance.failure = protect(ErrorInfo{Phase: "mount", Component: id}, func() {
+			initial = component.GetInitialState(props)
+		})
+		prefix := componentPrefix(id)
+		appInstance.state.seed(pageScope, prefix, appInstance.hydration.revive(prefix, initial))
+	}
+	instance.ctx.Props = props
+	instance.rendered = true
+
+	// A failing component only replaces its own markup with the error view, the page keeps working
+	var body string
+	if instance.failure == nil {
+		instance.failure = protect(ErrorInfo{Phase: "render", Component: id}, func() {
+			body = instance.component.Render(instance.ctx)
+		})
+	}
+	if instance.failure != nil {
+		body = renderErrorView(*instance.failure)
+	}
+	return `<div ` + componentAttr + `="` + html.EscapeString(id) + `" style="display:contents">` + body + `</div>`
+}
+
+// handleComponentEvent routes an event fired inside a component's markup to its instance
+func (a *app) handleComponentEvent(id, eventName string, event Event) {
+	instance, exists := a.components[id]
+	if !exists || instance.failure != nil {
+		return
+	}
+	a.batch(func() {
+		a.state.setCause(id + ":" + eventName)
+		defer a.state.setCause("")
+		instance.failure = protect(ErrorInfo{Phase: "event", Component: id, Event: eventName}, func() {
+			instance.component.HandleEvent(instance.ctx, eventName, event)
+		})
+		a.invalidate()
+	})
 }
 
-// Render provides a default render method
-func (p *BasePage) Render() string {
-	return StencilText.Titre1("Override the Render method in your page")
+// sweepComponents disposes of the instances that were not embedded by the last render
+func (a *app) sweepComponents() {
+	for id, instance := range a.components {
+		if !instance.rendered {
+			delete(a.components, id)
+			// Only this instance's keys go: the prefix ends with the id's terminator
+			a.state.deletePrefix(pageScope, componentPrefix(id))
+			continue
+		}
+		instance.rendered = false
+	}
 }
@@ -0,0 +1,92 @@
+package framework
+
+import (
+	"fmt"
+	"testing"
+)
+
+// counterComponent counts the clicks on its button
+type counterComponent struct {
+	BaseComponent
+}
+
+func (c *counterComponent) GetInitialState(props Props) map[string]interface{} {
+	return map[string]interface{}{"count": 0}
+}
+
+func (c *counterComponent) Render(ctx *ComponentContext) string {
+	return fmt.Sprintf(`<button data-onclick="increment">%d</button>`, ctx.GetInt("count"))
+}
+
+func (c *counterComponent) HandleEvent(ctx *ComponentContext, eventName string, event Event) {
+	if eventName == "increment" {
+		ctx.Set("count", ctx.GetInt("count")+1)
+	}
+}
+
+// nestedIdsPage embeds two counters whose ids overlap, next to a page key
+// named like the local state of the first one
+type nestedIdsPage struct {
+	BasePage
+}
+
+func (p *nestedIdsPage) GetInitialState() map[string]interface{} {
+	return map[string]interface{}{"showA": true, "a.count": 100}
+}
+
+func (p *nestedIdsPage) Render() string {
+	html := Embed("a.b", &counterComponent{}, nil)
+	if GetStateBool("showA") {
+		html += Embed("a", &counterComponent{}, nil)
+	}
+	return html
+}
+
+func clickComponent(t *testing.T, h *Headless, id string) {
+	t.Helper()
+	found, err := h.Query(fmt.Sprintf(`[data-stencil-component=%q] button`, id))
+	if err != nil || len(found) == 0 {
+		t.Fatalf("no button in component %q:\n%s", id, h.HTML())
+	}
+	h.Dispatch(found[0], EventInit{Type: "click"})
+}
+
+func TestComponentStateIsolation(t *testing.T) {
+	h := NewHeadless()
+	defer h.Close()
+	h.Mount(&nestedIdsPage{})
+
+	clickComponent(t, h, "a")
+	clickComponent(t, h, "a.b")
+	clickComponent(t, h, "a.b")
+
+	if got := GetStateInt("a.count"); got != 100 {
+		t.Errorf("page key a.count = %d, want 100", got)
+	}
+	if got, _ := Lookup(NewKey[int](ComponentKey("a", "count"))); got != 1 {
+		t.Errorf("count of a = %d, want 1", got)
+	}
+	if got, _ := Lookup(NewKey[int](ComponentKey("a.b", "count"))); got != 2 {
+		t.Errorf("count of a.b = %d, want 2", got)
+	}
+
+	// Unmounting a disposes of its state only
+	SetState("showA", false)
+	h.Flush()
+	if _, err := Lookup(NewKey[int](ComponentKey("a", "count"))); err == nil {
+		t.Error("the state of a survived its unmount")
+	}
+	if got, _ := Lookup(NewKey[int](ComponentKey("a.b", "count"))); got != 2 {
+		t.Errorf("count of a.b = %d after a unmounted, want 2", got)
+	}
+	if got := GetStateInt("a.count"); got != 100 {
+		t.Errorf("page key a.count = %d after a unmounted, want 100", got)
+	}
+
+	// Embedding a again starts from its initial state
+	SetState("showA", true)
+	h.Flush()
+	if got, _ := Lookup(NewKey[int](ComponentKey("a", "count"))); got != 0 {
+		t.Errorf("count of a = %d after it was embedded again, want 0", got)
+	}
+}
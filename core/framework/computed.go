@@ -0,0 +1,83 @@
+package framework
+
+import "sync"
+
+// Dependency is a state entry that a computed value depends on.
+// Every Key is a Dependency, untyped keys can be declared as NewKey[any]("items").
+type Dependency interface {
+	stateEntry() entry
+}
+
+func (k Key[T]) stateEntry() entry {
+	return entry{scope: k.scope, key: k.name}
+}
+
+// ComputedValue is a value derived from the state and memoized on the
+// versions of its dependencies
+type ComputedValue[T any] struct {
+	mu       sync.Mutex
+	deps     []Dependency
+	fn       func() T
+	versions []uint64
+	value    T
+	valid    bool
+}
+
+// Computed declares a value derived from the state, such as a filtered list or a total.
+// fn is only called again once one of deps has changed, so declare computed
+// values once and call Get from Render:
+//
+//	var visible = framework.Computed([]framework.Dependency{todos, filter}, func() []Todo {
+//		return filterTodos(framework.Get(todos), framework.Get(filter))
+//	})
+//
+// fn must only read the state through deps, other reads are not tracked.
+func Computed[T any](deps []Dependency, fn func() T) *ComputedValue[T] {
+	return &ComputedValue[T]{deps: deps, fn: fn}
+}
+
+// Get returns the current value, recomputing it only if a dependency changed
+func (c *ComputedValue[T]) Get() T {
+	versions, ok := dependencyVersions(c.deps)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok && c.valid && equalVersions(c.versions, versions) {
+		return c.value
+	}
+	value := c.fn()
+	// Values computed from queued writes are not memoized, the writes are not committed yet
+	if ok {
+		c.value, c.versions, c.valid = value, versions, true
+	}
+	return value
+}
+
+// dependencyVersions returns the committed version of every dependency.
+// ok is false when one of them has a queued write or there is no app.
+func dependencyVersions(deps []Dependency) (versions []uint64, ok bool) {
+	if appInstance == nil {
+		return nil, false
+	}
+	versions = make([]uint64, len(deps))
+	for i, dep := range deps {
+		e := dep.stateEntry()
+		if versions[i], ok = appInstance.state.version(e.scope, e.key); !ok {
+			return nil, false
+		}
+	}
+	return versions, true
+}
+
+func equalVersions(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
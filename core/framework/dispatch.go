@@ -0,0 +1,110 @@
+package framework
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// dispatchDOMEvent routes an event fired in the container to the page through its data-on<event> attribute
+func (a *app) dispatchDOMEvent(event host.Event) {
+	target := event.Target()
+	if target == nil {
+		return
+	}
+	eventType := event.Type()
+
+	// Bound form controls update the state before any handler runs
+	if eventType == "input" || eventType == "change" {
+		a.applyBinding(target)
+	}
+
+	attr := eventAttrPrefix + eventType
+	var el host.Element
+	if nonBubblingEvents[eventType] {
+		if _, exists := target.Attr(attr); exists {
+			el = target
+		}
+	} else {
+		el = target.Closest("[" + attr + "]")
+	}
+
+	if el != nil && a.container.Contains(el) {
+		if eventType == "submit" {
+			// Forms handled by the page never trigger a full page load
+			event.PreventDefault()
+		}
+		eventName, _ := el.Attr(attr)
+		wrapped := newHostEvent(event, el)
+		// Events fired inside a component's markup belong to that component instance
+		if component := el.Closest("[" + componentAttr + "]"); component != nil && a.container.Contains(component) {
+			id, _ := component.Attr(componentAttr)
+			a.handleComponentEvent(id, eventName, wrapped)
+			return
+		}
+		// Events fired in a layout's own markup, outside of its slot, belong to the layout
+		if layout := el.Closest("[" + layoutAttr + "], [" + slotAttr + "]"); layout != nil && a.container.Contains(layout) {
+			if id, isLayout := layout.Attr(layoutAttr); isLayout {
+				a.handleLayoutEvent(id, eventName, wrapped)
+				return
+			}
+		}
+		a.handleEvent(eventName, wrapped)
+		return
+	}
+
+	if eventType == "click" {
+		a.interceptLink(event, target)
+	}
+}
+
+// interceptLink turns clicks on internal links into client side navigation
+func (a *app) interceptLink(event host.Event, target host.Element) {
+	if event.DefaultPrevented() || event.Button() != 0 || event.Modifiers() != (Modifiers{}) {
+		return
+	}
+
+	link := target.Closest("a[href^='/']:not([href^='//'])")
+	if link == nil || !a.container.Contains(link) {
+		return
+	}
+	if _, exists := link.Attr("download"); exists {
+		return
+	}
+	if t, _ := link.Attr("target"); t != "" && t != "_self" {
+		return
+	}
+
+	href, _ := link.Attr("href")
+	u, err := url.Parse(href)
+	if err != nil {
+		return
+	}
+	event.PreventDefault()
+
+	// Navigate using router, the query string is kept
+	if globalRouter != nil {
+		globalRouter.Navigate(joinLocation(u.Path, u.RawQuery))
+	}
+}
+
+// collectEventTypes appends the event types referenced by data-on<event> attributes in nodes
+func collectEventTypes(nodes []*vnode, types []string) []string {
+	for _, node := range nodes {
+		if node.kind != elementNode {
+			continue
+		}
+		for _, attr := range node.attrs {
+			if !strings.HasPrefix(attr.name, eventAttrPrefix) || len(attr.name) == len(eventAttrPrefix) {
+				continue
+			}
+			eventType := attr.name[len(eventAttrPrefix):]
+			if !containsString(types, eventType) {
+				types = append(types, eventType)
+			}
+		}
+		types = collectEventTypes(node.children, types)
+	}
+	return types
+}
@@ -0,0 +1,222 @@
+package framework
+
+import (
+	"net/url"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// eventAttrPrefix is the attribute prefix that binds a DOM event to a page event name.
+// For example data-onclick="save" or data-onkeydown="search".
+const eventAttrPrefix = "data-on"
+
+// nonBubblingEvents must be captured on the container and only match their target
+var nonBubblingEvents = map[string]bool{
+	"focus":        true,
+	"blur":         true,
+	"mouseenter":   true,
+	"mouseleave":   true,
+	"pointerenter": true,
+	"pointerleave": true,
+	"load":         true,
+	"error":        true,
+	"scroll":       true,
+}
+
+// Modifiers reports the modifier keys held when an event fired
+type Modifiers = host.Modifiers
+
+// Event is the event passed to HandleEvent.
+// It gives typed access to the DOM event and to the element that declared the
+// data-on<event> attribute, so pages never need to import syscall/js.
+type Event struct {
+	// Type is the DOM event type, such as "click", "input" or "keydown"
+	Type string
+
+	source eventSource
+}
+
+// eventSource provides the data behind an Event, in the browser or in tests
+type eventSource interface {
+	value() string
+	checked() bool
+	key() string
+	modifiers() Modifiers
+	formValues() url.Values
+	dataset() map[string]string
+	preventDefault()
+	stopPropagation()
+	defaultPrevented() bool
+}
+
+// Value returns the value of the event target (inputs, selects and textareas)
+func (e Event) Value() string {
+	if e.source == nil {
+		return ""
+	}
+	return e.source.value()
+}
+
+// Checked returns the checked state of the event target (checkboxes and radios)
+func (e Event) Checked() bool {
+	if e.source == nil {
+		return false
+	}
+	return e.source.checked()
+}
+
+// Key returns the key of a keyboard event, such as "Enter" or "a"
+func (e Event) Key() string {
+	if e.source == nil {
+		return ""
+	}
+	return e.source.key()
+}
+
+// Modifiers returns the modifier keys held during the event
+func (e Event) Modifiers() Modifiers {
+	if e.source == nil {
+		return Modifiers{}
+	}
+	return e.source.modifiers()
+}
+
+// FormValues returns the values of the form the event belongs to.
+// For a submit event it is the submitted form, otherwise the form owning the target.
+func (e Event) FormValues() url.Values {
+	if e.source == nil {
+		return url.Values{}
+	}
+	return e.source.formValues()
+}
+
+// Dataset returns the data-* attributes of the element that declared the handler
+func (e Event) Dataset() map[string]string {
+	if e.source == nil {
+		return map[string]string{}
+	}
+	return e.source.dataset()
+}
+
+// PreventDefault cancels the default browser action of the event
+func (e Event) PreventDefault() {
+	if e.source != nil {
+		e.source.preventDefault()
+	}
+}
+
+// StopPropagation stops the event from reaching other listeners
+func (e Event) StopPropagation() {
+	if e.source != nil {
+		e.source.stopPropagation()
+	}
+}
+
+// DefaultPrevented reports whether PreventDefault was called
+func (e Event) DefaultPrevented() bool {
+	if e.source == nil {
+		return false
+	}
+	return e.source.defaultPrevented()
+}
+
+// hostEvent is an eventSource backed by an event of the application host
+type hostEvent struct {
+	event   host.Event
+	element host.Element // element that declared the data-on<event> attribute
+}
+
+// newHostEvent wraps a host event dispatched to element
+func newHostEvent(event host.Event, element host.Element) Event {
+	return Event{Type: event.Type(), source: &hostEvent{event: event, element: element}}
+}
+
+func (s *hostEvent) value() string {
+	if target := s.event.Target(); target != nil {
+		return target.Value()
+	}
+	return ""
+}
+
+func (s *hostEvent) checked() bool {
+	if target := s.event.Target(); target != nil {
+		return target.Checked()
+	}
+	return false
+}
+
+func (s *hostEvent) key() string            { return s.event.Key() }
+func (s *hostEvent) modifiers() Modifiers   { return s.event.Modifiers() }
+func (s *hostEvent) preventDefault()        { s.event.PreventDefault() }
+func (s *hostEvent) stopPropagation()       { s.event.StopPropagation() }
+func (s *hostEvent) defaultPrevented() bool { return s.event.DefaultPrevented() }
+
+func (s *hostEvent) formValues() url.Values {
+	target := s.event.Target()
+	if target == nil {
+		return url.Values{}
+	}
+	// A submit event fires on the form itself
+	form := target
+	if s.event.Type() != "submit" {
+		form = target.Form()
+	}
+	if form == nil || form.Tag() != "form" {
+		return url.Values{}
+	}
+	return form.FormValues()
+}
+
+func (s *hostEvent) dataset() map[string]string {
+	if s.element == nil {
+		return map[string]string{}
+	}
+	return s.element.Dataset()
+}
+
+// EventInit describes an event built outside the browser, for example in tests
+type EventInit struct {
+	Type      string
+	Value     string
+	Checked   bool
+	Key       string
+	Modifiers Modifiers
+	Form      url.Values
+	Dataset   map[string]string
+}
+
+// NewEvent creates an Event that does not depend on the browser
+func NewEvent(init EventInit) Event {
+	return Event{Type: init.Type, source: &staticEvent{init: init}}
+}
+
+// staticEvent is an eventSource backed by an EventInit
+type staticEvent struct {
+	init      EventInit
+	prevented bool
+	stopped   bool
+}
+
+func (s *staticEvent) value() string          { return s.init.Value }
+func (s *staticEvent) checked() bool          { return s.init.Checked }
+func (s *staticEvent) key() string            { return s.init.Key }
+func (s *staticEvent) modifiers() Modifiers   { return s.init.Modifiers }
+func (s *staticEvent) preventDefault()        { s.prevented = true }
+func (s *staticEvent) stopPropagation()       { s.stopped = true }
+func (s *staticEvent) defaultPrevented() bool { return s.prevented }
+
+func (s *staticEvent) formValues() url.Values {
+	values := url.Values{}
+	for key, list := range s.init.Form {
+		values[key] = append([]string(nil), list...)
+	}
+	return values
+}
+
+func (s *staticEvent) dataset() map[string]string {
+	dataset := make(map[string]string, len(s.init.Dataset))
+	for key, value := range s.init.Dataset {
+		dataset[key] = value
+	}
+	return dataset
+}
@@ -0,0 +1,76 @@
+//go:build js && wasm
+
+package framework
+
+import "syscall/js"
+
+// JS returns the underlying browser event, or undefined for events built with NewEvent.
+// Prefer the typed helpers, this is an escape hatch for code that still needs syscall/js.
+func (e Event) JS() js.Value {
+	if source, ok := e.source.(*hostEvent); ok {
+		if event, ok := source.event.(*jsEvent); ok {
+			return event.event
+		}
+	}
+	return js.Undefined()
+}
+
+// LegacyPage is the page interface used before Event was introduced,
+// with a HandleEvent method that receives the raw browser event.
+type LegacyPage interface {
+	Render() string
+	HandleEvent(eventName string, event js.Value)
+	GetInitialState() map[string]interface{}
+}
+
+// Legacy adapts a page written against the syscall/js event signature so it can
+// be registered with the router while it is being migrated to Event:
+//
+//	framework.RegisterRoute("/old", func() framework.PageInterface {
+//		return framework.Legacy(&OldPage{})
+//	})
+func Legacy(page LegacyPage) PageInterface {
+	return &legacyPage{page: page}
+}
+
+// legacyPage forwards PageInterface and the lifecycle hooks to a LegacyPage
+type legacyPage struct {
+	page LegacyPage
+}
+
+func (p *legacyPage) Render() string {
+	return p.page.Render()
+}
+
+func (p *legacyPage) GetInitialState() map[string]interface{} {
+	return p.page.GetInitialState()
+}
+
+func (p *legacyPage) HandleEvent(eventName string, event Event) {
+	p.page.HandleEvent(eventName, event.JS())
+}
+
+func (p *legacyPage) OnMount() {
+	if mounter, ok := p.page.(Mounter); ok {
+		mounter.OnMount()
+	}
+}
+
+func (p *legacyPage) OnUnmount() {
+	if unmounter, ok := p.page.(Unmounter); ok {
+		unmounter.OnUnmount()
+	}
+}
+
+func (p *legacyPage) OnUpdate() {
+	if updater, ok := p.page.(Updater); ok {
+		updater.OnUpdate()
+	}
+}
+
+func (p *legacyPage) BeforeLeave(to string) bool {
+	if guard, ok := p.page.(LeaveGuard); ok {
+		return guard.BeforeLeave(to)
+	}
+	return true
+}
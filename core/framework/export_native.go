@@ -0,0 +1,195 @@
+//go:build !(js && wasm)
+
+package framework
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportOptions configures ExportStatic
+type ExportOptions struct {
+	// OutDir receives the exported site, "dist" by default
+	OutDir string
+	// Index is the HTML shell the pages are rendered into, "core/index.html" by default
+	Index string
+	// StaticDir holds app.wasm and wasm_exec.js, "core" by default
+	StaticDir string
+	// ContainerID is the id of the element the pages are rendered into, "app" by default
+	ContainerID string
+	// BaseURL is the public URL of the site, such as "https://example.com".
+	// Sitemaps require absolute locations, so sitemap.xml is only written when it is set.
+	BaseURL string
+}
+
+// staticAssets are copied from StaticDir next to the exported pages
+var staticAssets = []string{"app.wasm", "wasm_exec.js"}
+
+// ExportStatic pre-renders every registered route to <OutDir>/<route>/index.html,
+// with its serialized state, and copies app.wasm and wasm_exec.js next to the
+// pages. It also writes a 404.html page and, when BaseURL is set, a
+// sitemap.xml listing the routes, so the site can be served from any static
+// file host, with no SPA fallback.
+// It returns the written files, relative to OutDir.
+//
+// Routes must be registered before the export:
+//
+//	app.RegisterRoutes()
+//	files, err := framework.ExportStatic(framework.ExportOptions{BaseURL: "https://example.com"})
+func ExportStatic(options ...ExportOptions) ([]string, error) {
+	var o ExportOptions
+	if len(options) > 0 {
+		o = options[0]
+	}
+	if o.OutDir == "" {
+		o.OutDir = "dist"
+	}
+	if o.Index == "" {
+		o.Index = "core/index.html"
+	}
+	if o.StaticDir == "" {
+		o.StaticDir = "core"
+	}
+	if o.ContainerID == "" {
+		o.ContainerID = "app"
+	}
+	if o.BaseURL != "" {
+		if u, err := url.Parse(o.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("export: BaseURL %q is not an absolute URL", o.BaseURL)
+		}
+	}
+
+	shell, err := os.ReadFile(o.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	write := func(name string, snapshot Snapshot) error {
+		document, err := snapshot.Document(string(shell), o.ContainerID)
+		if err != nil {
+			return err
+		}
+		if err := writeExportFile(o.OutDir, name, []byte(document)); err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	}
+
+	// Routes with parameters cannot be listed, their pages are rendered in the browser from 404.html
+	var paths []string
+	for _, path := range GetRouter().Paths() {
+		if !isRoutePattern(path) {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		snapshot := Prerender(path)
+		if snapshot.Err != nil {
+			// Publishing the error view would hide the failure until a visitor hits it
+			return files, fmt.Errorf("export %s: %w", path, snapshot.Err)
+		}
+		if err := write(exportFileName(path), snapshot); err != nil {
+			return files, fmt.Errorf("export %s: %w", path, err)
+		}
+	}
+	// Static hosts serve 404.html for unknown paths, the router then takes over in the browser
+	if err := write("404.html", prerenderPage("", GetRouter().notFoundPage(""), nil, statusNotFound)); err != nil {
+		return files, fmt.Errorf("export 404.html: %w", err)
+	}
+
+	for _, asset := range staticAssets {
+		if err := copyExportFile(filepath.Join(o.StaticDir, asset), o.OutDir, asset); err != nil {
+			return files, err
+		}
+		files = append(files, asset)
+	}
+
+	if o.BaseURL == "" {
+		warn("stencil: no BaseURL, sitemap.xml is not written: sitemaps require absolute URLs")
+		return files, nil
+	}
+	sitemap, err := renderSitemap(o.BaseURL, paths)
+	if err != nil {
+		return files, err
+	}
+	if err := writeExportFile(o.OutDir, "sitemap.xml", sitemap); err != nil {
+		return files, err
+	}
+	return append(files, "sitemap.xml"), nil
+}
+
+// exportFileName returns the file a route is exported to, relative to the output directory
+func exportFileName(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "index.html"
+	}
+	return path + "/index.html"
+}
+
+// writeExportFile writes a file of the exported site, creating its directories
+func writeExportFile(dir, name string, data []byte) error {
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// copyExportFile copies a static file into the exported site
+func copyExportFile(src, dir, name string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s not found, build the app first", src)
+		}
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// sitemapURLSet is the root element of sitemap.xml
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// renderSitemap lists the exported routes in the sitemaps.org format
+func renderSitemap(baseURL string, paths []string) ([]byte, error) {
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	for _, path := range paths {
+		set.URLs = append(set.URLs, sitemapURL{Loc: baseURL + path})
+	}
+
+	data, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,111 @@
+//go:build !(js && wasm)
+
+package framework
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// aboutPage is a static page exported by the tests
+type aboutPage struct {
+	BasePage
+}
+
+func (p *aboutPage) Render() string {
+	return `<h1>About</h1>`
+}
+
+// exportOptions registers the routes of the tests and prepares the shell and the static assets
+func exportOptions(t *testing.T, baseURL string) ExportOptions {
+	t.Helper()
+	RegisterRoute("/", func() PageInterface { return &aboutPage{} })
+	RegisterRoute("/about", func() PageInterface { return &aboutPage{} })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":   `<html><body><div id="app"></div></body></html>`,
+		"app.wasm":     "wasm",
+		"wasm_exec.js": "js",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ExportOptions{
+		OutDir:    filepath.Join(dir, "dist"),
+		Index:     filepath.Join(dir, "index.html"),
+		StaticDir: dir,
+		BaseURL:   baseURL,
+	}
+}
+
+func TestExportWithoutBaseURLSkipsSitemap(t *testing.T) {
+	options := exportOptions(t, "")
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	files, err := ExportStatic(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if slices.Contains(files, "sitemap.xml") {
+		t.Errorf("files = %v, want no sitemap.xml", files)
+	}
+	if _, err := os.Stat(filepath.Join(options.OutDir, "sitemap.xml")); !os.IsNotExist(err) {
+		t.Errorf("sitemap.xml was written without a BaseURL")
+	}
+	if !strings.Contains(logs.String(), "sitemap.xml") {
+		t.Errorf("no warning about the missing sitemap in %q", logs.String())
+	}
+	if !slices.Contains(files, filepath.Join("about", "index.html")) {
+		t.Errorf("files = %v, want the about page", files)
+	}
+}
+
+func TestExportSitemapHasAbsoluteURLs(t *testing.T) {
+	options := exportOptions(t, "https://example.com/")
+
+	files, err := ExportStatic(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(files, "sitemap.xml") {
+		t.Fatalf("files = %v, want sitemap.xml", files)
+	}
+	sitemap, err := os.ReadFile(filepath.Join(options.OutDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locs := regexp.MustCompile(`<loc>(.*?)</loc>`).FindAllStringSubmatch(string(sitemap), -1)
+	var got []string
+	for _, loc := range locs {
+		got = append(got, loc[1])
+	}
+	for _, want := range []string{"https://example.com/", "https://example.com/about"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("sitemap locations %v miss %s", got, want)
+		}
+	}
+	for _, loc := range got {
+		if !strings.HasPrefix(loc, "https://example.com/") {
+			t.Errorf("sitemap location %q is not absolute", loc)
+		}
+	}
+}
+
+func TestExportRejectsRelativeBaseURL(t *testing.T) {
+	if _, err := ExportStatic(exportOptions(t, "/site")); err == nil {
+		t.Error("a relative BaseURL was accepted")
+	}
+}
@@ -0,0 +1,513 @@
+// Package forms declares forms as a list of fields with validation rules.
+//
+// A Form is a component: it renders its fields with the Stencil-Go builders,
+// binds them to its local state and keeps track, for every field, of whether
+// it was touched (focused then left), whether it is dirty (differs from its
+// initial value) and of its current error. Errors live in the page state, so
+// the page can read them back through the Form accessors.
+//
+//	var signup = forms.New("signup",
+//		forms.Field{Name: "email", Label: "Email", Type: "email", Rules: []forms.Rule{forms.Required(), forms.Email()}},
+//		forms.Field{Name: "password", Label: "Password", Type: "password", Rules: []forms.Rule{forms.MinLength(8)}},
+//	).OnSubmit(createAccount)
+//
+//	func (p *SignupPage) Render() string {
+//		return signup.View()
+//	}
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	StencilForm "github.com/RafaelCoppe/Stencil-Go/pkg/form"
+	StencilInteractions "github.com/RafaelCoppe/Stencil-Go/pkg/interactions"
+	StencilPage "github.com/RafaelCoppe/Stencil-Go/pkg/page"
+	StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
+	StencilUtils "github.com/RafaelCoppe/Stencil-Go/pkg/utils"
+)
+
+// Values holds the value of every field of a form, keyed by field name.
+// Checkboxes read as "true" when checked and "" otherwise.
+type Values map[string]string
+
+// Option is a choice of a select field
+type Option struct {
+	Value string
+	Label string
+}
+
+// Field declares a form field
+type Field struct {
+	Name  string
+	Label string
+	// Type is the input type: text (default), email, password, number, date,
+	// textarea, select or checkbox
+	Type        string
+	Placeholder string
+	Options     []Option // choices of a select field, in display order
+	Initial     string   // initial value, "true" checks a checkbox
+	Rules       []Rule
+	Async       []AsyncRule
+}
+
+// FieldErrors can be returned by a submit handler to report errors on
+// individual fields, for example when the server rejects a value
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + ": " + e[field]
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Form is a declarative form embedded in a page as a component
+type Form struct {
+	framework.BaseComponent
+
+	id          string
+	fields      []Field
+	submitText  string
+	pendingText string
+	onSubmit    func(Values) error
+}
+
+// New declares a form. id identifies the form instance in the page, like the
+// id given to framework.Embed.
+func New(id string, fields ...Field) *Form {
+	return &Form{
+		id:          id,
+		fields:      fields,
+		submitText:  "Submit",
+		pendingText: "Submitting…",
+	}
+}
+
+// SubmitText sets the label of the submit button
+func (f *Form) SubmitText(text string) *Form {
+	f.submitText = text
+	return f
+}
+
+// PendingText sets the label of the submit button while the submission is pending
+func (f *Form) PendingText(text string) *Form {
+	f.pendingText = text
+	return f
+}
+
+// OnSubmit sets the function called with the values of a valid form.
+// It runs in its own goroutine, so it can block on network calls; the submit
+// button stays disabled until it returns. A FieldErrors error is reported on
+// the fields, any other error, or a panic, is reported above the form.
+func (f *Form) OnSubmit(fn func(Values) error) *Form {
+	f.onSubmit = fn
+	return f
+}
+
+// View renders the form, it is meant to be called from a page's Render method
+func (f *Form) View() string {
+	return framework.Embed(f.id, f, nil)
+}
+
+// Values returns the current value of every field
+func (f *Form) Values() Values {
+	values := make(Values, len(f.fields))
+	for _, field := range f.fields {
+		values[field.Name] = f.value(field.Name)
+	}
+	return values
+}
+
+// Error returns the error currently reported on a field
+func (f *Form) Error(name string) string {
+	value, _ := framework.Lookup(framework.NewKey[string](f.key("error." + name)))
+	return value
+}
+
+// Errors returns the errors currently reported, keyed by field name
+func (f *Form) Errors() map[string]string {
+	errs := make(map[string]string)
+	for _, field := range f.fields {
+		if msg := f.Error(field.Name); msg != "" {
+			errs[field.Name] = msg
+		}
+	}
+	return errs
+}
+
+// Touched reports whether a field has been left after being focused, or the form submitted
+func (f *Form) Touched(name string) bool {
+	return f.flag("touched." + name)
+}
+
+// Dirty reports whether a field differs from its initial value
+func (f *Form) Dirty(name string) bool {
+	return f.flag("dirty." + name)
+}
+
+// Pending reports whether a submission is in progress
+func (f *Form) Pending() bool {
+	return f.flag("pending")
+}
+
+// Submitted reports whether the last submission succeeded
+func (f *Form) Submitted() bool {
+	return f.flag("submitted")
+}
+
+// SubmitError returns the error returned by the last submission, if any
+func (f *Form) SubmitError() string {
+	value, _ := framework.Lookup(framework.NewKey[string](f.key("submitError")))
+	return value
+}
+
+// Reset restores the initial values and clears the touched, dirty and error state.
+// It can be called from the submit handler to empty the form after a success.
+func (f *Form) Reset() {
+	for _, field := range f.fields {
+		f.set("value."+field.Name, field.initialState())
+		f.set("touched."+field.Name, false)
+		f.set("dirty."+field.Name, false)
+		f.set("error."+field.Name, "")
+		f.set("checking."+field.Name, false)
+	}
+	f.set("submitted", false)
+	f.set("submitError", "")
+}
+
+// GetInitialState seeds the bound value of every field
+func (f *Form) GetInitialState(props framework.Props) map[string]interface{} {
+	state := make(map[string]interface{}, len(f.fields))
+	for _, field := range f.fields {
+		state["value."+field.Name] = field.initialState()
+	}
+	return state
+}
+
+// HandleEvent tracks the field state and handles the submission
+func (f *Form) HandleEvent(ctx *framework.ComponentContext, eventName string, event framework.Event) {
+	switch eventName {
+	case "change":
+		field, ok := f.field(event.Dataset()["field"])
+		if !ok {
+			return
+		}
+		values := f.Values()
+		f.set("dirty."+field.Name, values[field.Name] != field.initialValue())
+		// Touched fields are all checked again so that cross-field errors follow both fields
+		f.validate(values, false)
+
+	case "blur":
+		field, ok := f.field(event.Dataset()["field"])
+		if !ok {
+			return
+		}
+		f.set("touched."+field.Name, true)
+		values := f.Values()
+		if errs := f.validate(values, false); errs[field.Name] == "" && len(field.Async) > 0 {
+			f.checkAsync(field, values)
+		}
+
+	case "submit":
+		if f.Pending() {
+			return
+		}
+		for _, field := range f.fields {
+			f.set("touched."+field.Name, true)
+		}
+		values := f.Values()
+		if errs := f.validate(values, true); len(errs) > 0 {
+			return
+		}
+		f.set("pending", true)
+		f.set("submitted", false)
+		f.set("submitError", "")
+		go f.submit(values)
+	}
+}
+
+// validate runs the synchronous rules of the touched fields (or every field
+// when all is set), stores their errors and returns the fields in error
+func (f *Form) validate(values Values, all bool) map[string]string {
+	errs := make(map[string]string)
+	for _, field := range f.fields {
+		if !all && !f.Touched(field.Name) {
+			continue
+		}
+		msg := field.check(values)
+		f.set("error."+field.Name, msg)
+		if msg != "" {
+			errs[field.Name] = msg
+		}
+	}
+	return errs
+}
+
+// checkAsync runs the async rules of a field in the background.
+// The result is dropped if the value changed while the check was running.
+// A panicking rule is reported and shown as the field error.
+func (f *Form) checkAsync(field Field, values Values) {
+	f.set("checking."+field.Name, true)
+	go func() {
+		var msg string
+		failure := framework.Protect(framework.ErrorInfo{Phase: "validate", Component: f.id}, func() {
+			msg = field.checkAsync(values)
+		})
+		if failure != nil {
+			msg = failure.Err.Error()
+		}
+		f.set("checking."+field.Name, false)
+		if f.value(field.Name) == values[field.Name] {
+			f.set("error."+field.Name, msg)
+		}
+	}()
+}
+
+// submit runs the async rules then the submit handler, off the render loop.
+// A panic is reported and shown above the form, and the form can be submitted again.
+func (f *Form) submit(values Values) {
+	defer f.set("pending", false)
+
+	failure := framework.Protect(framework.ErrorInfo{Phase: "submit", Component: f.id}, func() {
+		f.runSubmit(values)
+	})
+	if failure != nil {
+		f.set("submitError", failure.Err.Error())
+	}
+}
+
+// runSubmit runs the async rules then the submit handler
+func (f *Form) runSubmit(values Values) {
+	valid := true
+	for _, field := range f.fields {
+		if msg := field.checkAsync(values); msg != "" {
+			f.set("error."+field.Name, msg)
+			valid = false
+		}
+	}
+	if !valid {
+		return
+	}
+
+	var err error
+	if f.onSubmit != nil {
+		err = f.onSubmit(values)
+	}
+	var fieldErrs FieldErrors
+	switch {
+	case errors.As(err, &fieldErrs):
+		for name, msg := range fieldErrs {
+			f.set("error."+name, msg)
+		}
+	case err != nil:
+		f.set("submitError", err.Error())
+	default:
+		f.set("submitted", true)
+	}
+}
+
+// Render renders the fields, the submission error and the submit button
+func (f *Form) Render(ctx *framework.ComponentContext) string {
+	pending := f.Pending()
+
+	elements := make([]string, 0, len(f.fields)+2)
+	if msg := f.SubmitError(); msg != "" {
+		elements = append(elements, StencilPage.Alert(html.EscapeString(msg), "danger", false))
+	}
+	for _, field := range f.fields {
+		elements = append(elements, f.renderField(field))
+	}
+
+	button := StencilInteractions.BoutonSubmit(html.EscapeString(f.submitText), "btn", "btn-primary")
+	if pending {
+		button = injectAttrs(StencilInteractions.BoutonSubmit(html.EscapeString(f.pendingText), "btn", "btn-primary"),
+			"button", `disabled`, `aria-busy="true"`)
+	}
+	elements = append(elements, button)
+
+	return injectAttrs(StencilForm.Form("", "post", elements), "form", `data-onsubmit="submit"`, `novalidate`)
+}
+
+// renderField renders a field with its label and error
+func (f *Form) renderField(field Field) string {
+	id := html.EscapeString(f.id + "-" + field.Name)
+	name := html.EscapeString(field.Name)
+	placeholder := html.EscapeString(field.Placeholder)
+	value := f.value(field.Name)
+	msg := f.Error(field.Name)
+
+	attrs := []string{
+		`id="` + id + `"`,
+		`data-bind="value.` + name + `"`,
+		`data-field="` + name + `"`,
+		`data-onblur="blur"`,
+	}
+	if field.Type == "checkbox" || field.Type == "select" {
+		attrs = append(attrs, `data-onchange="change"`)
+	} else {
+		attrs = append(attrs, `data-oninput="change"`)
+	}
+	if msg != "" {
+		attrs = append(attrs, `aria-invalid="true"`)
+	}
+	classes := func(base string) []string {
+		if msg != "" {
+			return []string{base, "is-invalid"}
+		}
+		return []string{base}
+	}
+
+	feedback := ""
+	if msg != "" {
+		feedback = StencilPage.Div(html.EscapeString(msg), "invalid-feedback", "d-block")
+	} else if f.flag("checking." + field.Name) {
+		feedback = StencilText.Small("Checking…", "form-text", "text-muted")
+	}
+
+	if field.Type == "checkbox" {
+		control := StencilForm.Checkbox(name, "true", html.EscapeString(field.Label), value != "", classes("form-check-input")...)
+		return StencilPage.Div(StencilUtils.Join(injectAttrs(control, "input", attrs...), feedback), "form-check", "mb-3")
+	}
+
+	var control string
+	switch field.Type {
+	case "textarea":
+		control = injectAttrs(StencilForm.TextArea(name, placeholder, html.EscapeString(value), 4, 0, classes("form-control")...), "textarea", attrs...)
+	case "select":
+		control = injectAttrs(StencilForm.Select(name, nil, "", classes("form-select")...), "select", attrs...)
+		control = strings.Replace(control, "</select>", renderOptions(field, value)+"</select>", 1)
+	default:
+		inputType := field.Type
+		if inputType == "" {
+			inputType = "text"
+		}
+		attrs = append(attrs, `value="`+html.EscapeString(value)+`"`)
+		control = injectAttrs(StencilForm.Input(html.EscapeString(inputType), name, placeholder, classes("form-control")...), "input", attrs...)
+	}
+
+	return StencilPage.Div(
+		StencilUtils.Join(
+			StencilForm.Label(id, html.EscapeString(field.Label), "form-label"),
+			control,
+			feedback,
+		),
+		"mb-3",
+	)
+}
+
+// renderOptions renders the options of a select field
+func renderOptions(field Field, selected string) string {
+	var b strings.Builder
+	if field.Placeholder != "" {
+		b.WriteString(`<option value="">` + html.EscapeString(field.Placeholder) + `</option>`)
+	}
+	for _, option := range field.Options {
+		b.WriteString(`<option value="` + html.EscapeString(option.Value) + `"`)
+		if option.Value == selected {
+			b.WriteString(` selected`)
+		}
+		b.WriteString(`>` + html.EscapeString(option.Label) + `</option>`)
+	}
+	return b.String()
+}
+
+// injectAttrs adds attributes to the first tag element of markup
+func injectAttrs(markup, tag string, attrs ...string) string {
+	i := strings.Index(markup, "<"+tag)
+	if i < 0 || len(attrs) == 0 {
+		return markup
+	}
+	i += len(tag) + 1
+	return markup[:i] + " " + strings.Join(attrs, " ") + markup[i:]
+}
+
+// field returns the field declared with name
+func (f *Form) field(name string) (Field, bool) {
+	for _, field := range f.fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// value returns the current value of a field as a string
+func (f *Form) value(name string) string {
+	switch v := framework.GetState(f.key("value." + name)).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "true"
+		}
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// key returns the page state key of a local state key of the form
+func (f *Form) key(key string) string {
+	return framework.ComponentKey(f.id, key)
+}
+
+func (f *Form) flag(key string) bool {
+	value, _ := framework.Lookup(framework.NewKey[bool](f.key(key)))
+	return value
+}
+
+func (f *Form) set(key string, value interface{}) {
+	framework.SetState(f.key(key), value)
+}
+
+// initialValue returns the initial value of the field as read by Values
+func (field Field) initialValue() string {
+	if field.Type == "checkbox" {
+		if field.Initial == "true" {
+			return "true"
+		}
+		return ""
+	}
+	return field.Initial
+}
+
+// initialState returns the initial value of the field as stored in the state
+func (field Field) initialState() interface{} {
+	if field.Type == "checkbox" {
+		return field.Initial == "true"
+	}
+	return field.Initial
+}
+
+// check runs the synchronous rules and returns the first error
+func (field Field) check(values Values) string {
+	for _, rule := range field.Rules {
+		if msg := rule(values[field.Name], values); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// checkAsync runs the async rules and returns the first error
+func (field Field) checkAsync(values Values) string {
+	for _, rule := range field.Async {
+		if msg := rule(values[field.Name], values); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package forms_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/forms"
+	stenciltest "github.com/RafaelCoppe/Stencil-Framework/core/framework/testing"
+)
+
+// formPage renders a single form
+type formPage struct {
+	framework.BasePage
+	form *forms.Form
+}
+
+func (p *formPage) Render() string {
+	return p.form.View()
+}
+
+// eventually flushes the goroutine writes until cond holds, or fails the test
+func eventually(t *testing.T, h *stenciltest.Harness, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		h.Flush()
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s:\n%s", what, h.HTML())
+}
+
+func TestRequiredRuleBlocksSubmit(t *testing.T) {
+	var submitted atomic.Int32
+	form := forms.New("signup", forms.Field{Name: "name", Label: "Name", Rules: []forms.Rule{forms.Required()}}).
+		OnSubmit(func(values forms.Values) error {
+			submitted.Add(1)
+			return nil
+		})
+	h := stenciltest.Mount(t, &formPage{form: form})
+
+	h.Submit("submit")
+
+	if got := form.Error("name"); got != "This field is required" {
+		t.Errorf("error of name = %q, want the required message", got)
+	}
+	h.AssertExists("#signup-name.is-invalid")
+	if form.Pending() {
+		t.Error("an invalid form is pending")
+	}
+
+	h.Input("#signup-name", "Ada")
+	h.Submit("submit")
+	eventually(t, h, "the submission", form.Submitted)
+	if got := submitted.Load(); got != 1 {
+		t.Errorf("submit handler ran %d times, want 1", got)
+	}
+	h.AssertMissing("#signup-name.is-invalid")
+}
+
+func TestAsyncRuleResolves(t *testing.T) {
+	release := make(chan struct{})
+	available := func(value string, values forms.Values) string {
+		<-release
+		if value == "admin" {
+			return "This username is taken"
+		}
+		return ""
+	}
+	form := forms.New("account", forms.Field{Name: "username", Label: "Username", Async: []forms.AsyncRule{available}})
+	h := stenciltest.Mount(t, &formPage{form: form})
+
+	h.Input("#account-username", "admin")
+	h.Fire("#account-username", framework.EventInit{Type: "blur"})
+	h.AssertContains("Checking…")
+
+	close(release)
+	eventually(t, h, "the async rule", func() bool { return form.Error("username") != "" })
+	if got := form.Error("username"); got != "This username is taken" {
+		t.Errorf("error of username = %q, want the async rule's message", got)
+	}
+	h.AssertNotContains("Checking…")
+}
+
+func TestSubmitPanicIsRecovered(t *testing.T) {
+	var mu sync.Mutex
+	var reported []framework.ErrorInfo
+	framework.SetErrorReporter(func(info framework.ErrorInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, info)
+	})
+	defer framework.SetErrorReporter(nil)
+
+	form := forms.New("contact", forms.Field{Name: "message", Label: "Message"}).
+		OnSubmit(func(values forms.Values) error {
+			panic("mail server unreachable")
+		})
+	h := stenciltest.Mount(t, &formPage{form: form})
+
+	h.Submit("submit")
+	eventually(t, h, "the end of the submission", func() bool { return !form.Pending() })
+
+	if got := form.SubmitError(); got != "mail server unreachable" {
+		t.Errorf("submit error = %q, want the panic message", got)
+	}
+	h.AssertContains("mail server unreachable")
+	h.AssertMissing("button[disabled]")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || reported[0].Phase != "submit" || reported[0].Component != "contact" {
+		t.Errorf("reported %+v, want one submit panic of the contact form", reported)
+	}
+}
@@ -0,0 +1,133 @@
+package forms
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule validates the value of a field.
+// It returns an error message, or "" when the value is valid. values holds
+// the current value of every field of the form, for cross-field checks.
+type Rule func(value string, values Values) string
+
+// AsyncRule validates a field off the render loop, typically by asking a server.
+// Async rules only run once the synchronous rules pass, when the field loses
+// focus and again when the form is submitted. Like Rule, it returns an error
+// message or "".
+type AsyncRule func(value string, values Values) string
+
+// WithMessage returns a copy of the rule that reports message instead of its default message
+func (r Rule) WithMessage(message string) Rule {
+	return func(value string, values Values) string {
+		if r(value, values) != "" {
+			return message
+		}
+		return ""
+	}
+}
+
+// Required rejects empty values, and unchecked checkboxes
+func Required() Rule {
+	return func(value string, values Values) string {
+		if strings.TrimSpace(value) == "" {
+			return "This field is required"
+		}
+		return ""
+	}
+}
+
+// MinLength rejects values shorter than n characters.
+// Like every rule except Required, it accepts empty values.
+func MinLength(n int) Rule {
+	return func(value string, values Values) string {
+		if value != "" && utf8.RuneCountInString(value) < n {
+			return fmt.Sprintf("Must be at least %d characters", n)
+		}
+		return ""
+	}
+}
+
+// MaxLength rejects values longer than n characters
+func MaxLength(n int) Rule {
+	return func(value string, values Values) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("Must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+// Min rejects numbers lower than min, and values that are not numbers
+func Min(min float64) Rule {
+	return numberRule(func(n float64) string {
+		if n < min {
+			return "Must be at least " + strconv.FormatFloat(min, 'f', -1, 64)
+		}
+		return ""
+	})
+}
+
+// Max rejects numbers greater than max, and values that are not numbers
+func Max(max float64) Rule {
+	return numberRule(func(n float64) string {
+		if n > max {
+			return "Must be at most " + strconv.FormatFloat(max, 'f', -1, 64)
+		}
+		return ""
+	})
+}
+
+// numberRule parses the value as a number before checking it
+func numberRule(check func(n float64) string) Rule {
+	return func(value string, values Values) string {
+		if value == "" {
+			return ""
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "Must be a number"
+		}
+		return check(n)
+	}
+}
+
+// Pattern rejects values that do not match the regular expression expr.
+// It panics if expr does not compile, like regexp.MustCompile.
+func Pattern(expr string) Rule {
+	re := regexp.MustCompile(expr)
+	return func(value string, values Values) string {
+		if value != "" && !re.MatchString(value) {
+			return "Invalid format"
+		}
+		return ""
+	}
+}
+
+// Email rejects values that are not a bare email address
+func Email() Rule {
+	return func(value string, values Values) string {
+		if value == "" {
+			return ""
+		}
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value || !strings.Contains(addr.Address[strings.LastIndexByte(addr.Address, '@')+1:], ".") {
+			return "Invalid email address"
+		}
+		return ""
+	}
+}
+
+// Equals rejects values that differ from the value of another field,
+// for example to confirm a password
+func Equals(field string) Rule {
+	return func(value string, values Values) string {
+		if value != values[field] {
+			return "Does not match " + field
+		}
+		return ""
+	}
+}
@@ -0,0 +1,140 @@
+package framework
+
+import (
+	"strings"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// Headless runs the application on a MemoryHost. Every render is parsed into
+// an in-memory document that can be queried with CSS selectors, and events
+// go through the same delegation as in the browser: bound controls update
+// the state, data-on<event> attributes reach HandleEvent and internal links
+// navigate. Lifecycle hooks run as usual.
+//
+// Headless backs the framework/testing package. It replaces the global app,
+// so only one Headless can be in use at a time.
+type Headless struct {
+	app  *app
+	host *MemoryHost
+}
+
+// NewHeadless creates an application on a MemoryHost and makes it the global app.
+// Routes registered with RegisterRoute are available to Open and Navigate.
+func NewHeadless() *Headless {
+	m := NewMemoryHost("/")
+	a, err := newApp(m, "app")
+	if err != nil {
+		// A memory host creates its containers on demand
+		panic(err)
+	}
+	globalRouter.currentPath = ""
+	globalRouter.currentQuery = ""
+	appInstance = a
+	return &Headless{app: a, host: m}
+}
+
+// Host returns the memory host the application runs on, for example to set its Fetcher
+func (h *Headless) Host() *MemoryHost {
+	return h.host
+}
+
+// Mount makes page the current page and renders it, like Run does in the browser
+func (h *Headless) Mount(page PageInterface) {
+	h.app.mount(page)
+	h.app.update()
+}
+
+// Open renders the route registered for path, like RunWithRouter does when the page loads.
+// The path may have a query string.
+func (h *Headless) Open(path string) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	h.host.PushHistory(path)
+	router := InitRouter()
+	router.currentPath, router.currentQuery = splitLocation(path)
+	router.render()
+}
+
+// Navigate navigates to path through the router, leave guards included
+func (h *Headless) Navigate(path string, options ...NavigateOptions) {
+	InitRouter().Navigate(path, options...)
+}
+
+// Back goes back to the previous location like the back button, leave guards included.
+// It reports false when there is no previous location.
+func (h *Headless) Back() bool {
+	defer h.Flush()
+	return h.host.Back()
+}
+
+// Path returns the current path, without the query string
+func (h *Headless) Path() string {
+	path, _ := splitLocation(h.host.Location())
+	return path
+}
+
+// Location returns the current path and query string
+func (h *Headless) Location() string {
+	return h.host.Location()
+}
+
+// Flush renders the pending state changes, including those made by goroutines
+func (h *Headless) Flush() {
+	h.host.RunFrames()
+	h.app.flush()
+}
+
+// Close unmounts the current page and releases the global app
+func (h *Headless) Close() {
+	h.app.unmountPage()
+	h.app.unmountLayouts(0)
+	stopPageWatchers()
+	h.app.stopListening()
+	if globalRouter.host == h.host {
+		globalRouter.detach()
+	}
+	if appInstance == h.app {
+		appInstance = nil
+	}
+}
+
+// HTML returns the HTML of the last render
+func (h *Headless) HTML() string {
+	return h.app.html
+}
+
+// Query returns the elements matching a CSS selector, in document order.
+// Elements reflect the render they were queried from, query them again after
+// an event to look at the next render.
+func (h *Headless) Query(selector string) ([]host.Element, error) {
+	if _, err := parseSelector(selector); err != nil {
+		return nil, err
+	}
+	return h.app.container.Query(selector), nil
+}
+
+// Dispatch fires events on el, in order, then renders the result once: like
+// in a browser, the click, input and change events of a checkbox all fire
+// before the next frame. The value, checked state, form values and dataset
+// of each event are read from the document, the EventInit only provides the
+// type, key and modifiers. Set values and check boxes on the element first.
+// It reports whether the default action of an event was prevented, which
+// is the case for the submits and internal links handled by the page.
+func (h *Headless) Dispatch(el host.Element, events ...EventInit) bool {
+	defer h.Flush()
+
+	target, ok := el.(*memoryElement)
+	if !ok || !h.app.container.Contains(target) {
+		return false
+	}
+
+	prevented := false
+	for _, init := range events {
+		event := &memoryEvent{eventType: init.Type, target: target, key: init.Key, modifiers: init.Modifiers}
+		target.container.dispatch(event)
+		prevented = prevented || event.prevented
+	}
+	return prevented
+}
@@ -0,0 +1,247 @@
+package framework
+
+import "sync"
+
+// HistoryOptions configures the state history recorder
+type HistoryOptions struct {
+	// Limit is the maximum number of mutations kept, 100 by default.
+	// The oldest steps are dropped first.
+	Limit int
+	// Keys restricts the recording to some keys, every key is recorded when empty.
+	// User-facing undo usually only covers the document being edited.
+	Keys []Dependency
+	// Overlay shows the time-travel overlay, meant for development builds
+	Overlay bool
+}
+
+// HistoryEntry is a recorded state mutation
+type HistoryEntry struct {
+	Key   string
+	App   bool // whether the key is app-wide
+	Old   interface{}
+	New   interface{}
+	Cause string // event whose handler made the change, "" for writes from goroutines
+}
+
+// historyStep groups the mutations committed by one render
+type historyStep []HistoryEntry
+
+// history records committed mutations so that they can be undone
+type history struct {
+	mu      sync.Mutex
+	options HistoryOptions
+	keys    map[entry]bool
+	steps   []historyStep
+	cursor  int // number of steps currently applied
+	size    int // number of entries in steps
+}
+
+// recorder is the history enabled with EnableHistory, nil when disabled
+var recorder struct {
+	sync.Mutex
+	history *history
+}
+
+// EnableHistory starts recording the state mutations of the current page.
+// Every render that commits changes becomes one step that Undo reverts and
+// Redo re-applies. The history is cleared when the router mounts another page.
+//
+//	framework.EnableHistory(framework.HistoryOptions{Limit: 200, Keys: []framework.Dependency{todos}})
+func EnableHistory(options ...HistoryOptions) {
+	h := &history{}
+	if len(options) > 0 {
+		h.options = options[0]
+	}
+	if h.options.Limit <= 0 {
+		h.options.Limit = 100
+	}
+	if len(h.options.Keys) > 0 {
+		h.keys = make(map[entry]bool, len(h.options.Keys))
+		for _, dep := range h.options.Keys {
+			h.keys[dep.stateEntry()] = true
+		}
+	}
+
+	recorder.Lock()
+	recorder.history = h
+	recorder.Unlock()
+}
+
+// DisableHistory stops recording and forgets the recorded mutations
+func DisableHistory() {
+	recorder.Lock()
+	recorder.history = nil
+	recorder.Unlock()
+	if appInstance != nil {
+		appInstance.renderHistoryOverlay()
+	}
+}
+
+// History returns the recorded mutations, oldest first, and the number of
+// them that are currently applied (the others have been undone)
+func History() (entries []HistoryEntry, applied int) {
+	h := activeHistory()
+	if h == nil {
+		return nil, 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, step := range h.steps {
+		entries = append(entries, step...)
+		if i < h.cursor {
+			applied += len(step)
+		}
+	}
+	return entries, applied
+}
+
+// CanUndo reports whether there is a step to undo
+func CanUndo() bool {
+	h := activeHistory()
+	if h == nil {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.cursor > 0
+}
+
+// CanRedo reports whether there is an undone step to re-apply
+func CanRedo() bool {
+	h := activeHistory()
+	if h == nil {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.cursor < len(h.steps)
+}
+
+// Undo reverts the last recorded step and reports whether there was one.
+// The restored values go through the normal write path: they are persisted,
+// watched and rendered on the next frame.
+func Undo() bool {
+	return travel(-1)
+}
+
+// Redo re-applies the last undone step and reports whether there was one
+func Redo() bool {
+	return travel(1)
+}
+
+// travel moves the history cursor by delta steps, replaying the values in between
+func travel(delta int) bool {
+	h := activeHistory()
+	if h == nil || appInstance == nil || delta == 0 {
+		return false
+	}
+	// Writes still queued form a step of their own that must be recorded first
+	appInstance.commit()
+
+	h.mu.Lock()
+	target := h.cursor + delta
+	if target < 0 {
+		target = 0
+	}
+	if target > len(h.steps) {
+		target = len(h.steps)
+	}
+	var replay []mutation
+	for h.cursor > target {
+		h.cursor--
+		step := h.steps[h.cursor]
+		for i := len(step) - 1; i >= 0; i-- {
+			replay = append(replay, step[i].mutation(step[i].Old, "undo"))
+		}
+	}
+	for h.cursor < target {
+		for _, e := range h.steps[h.cursor] {
+			replay = append(replay, e.mutation(e.New, "redo"))
+		}
+		h.cursor++
+	}
+	h.mu.Unlock()
+
+	for _, m := range replay {
+		appInstance.state.queue(m)
+	}
+	return len(replay) > 0
+}
+
+// travelTo moves the history cursor to step, used by the time-travel overlay
+func travelTo(step int) {
+	h := activeHistory()
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	delta := step - h.cursor
+	h.mu.Unlock()
+	travel(delta)
+}
+
+// mutation returns a replay write of value to the entry's key
+func (e HistoryEntry) mutation(value interface{}, cause string) mutation {
+	scope := pageScope
+	if e.App {
+		scope = appScope
+	}
+	return mutation{scope: scope, key: e.Key, value: value, cause: cause, replay: true}
+}
+
+// activeHistory returns the enabled history, or nil
+func activeHistory() *history {
+	recorder.Lock()
+	defer recorder.Unlock()
+	return recorder.history
+}
+
+// recordHistory records the mutations of a commit as one step
+func recordHistory(committed []mutation) {
+	h := activeHistory()
+	if h == nil || len(committed) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var step historyStep
+	for _, m := range committed {
+		// Undo and Redo move the cursor instead of adding steps
+		if m.replay || (h.keys != nil && !h.keys[entry{m.scope, m.key}]) {
+			continue
+		}
+		step = append(step, HistoryEntry{Key: m.key, App: m.scope == appScope, Old: m.old, New: m.value, Cause: m.cause})
+	}
+	if len(step) == 0 {
+		return
+	}
+
+	// A new change discards the undone steps
+	for _, undone := range h.steps[h.cursor:] {
+		h.size -= len(undone)
+	}
+	h.steps = append(h.steps[:h.cursor], step)
+	h.size += len(step)
+	h.cursor++
+
+	for h.size > h.options.Limit && len(h.steps) > 1 {
+		h.size -= len(h.steps[0])
+		h.steps = h.steps[1:]
+		h.cursor--
+	}
+}
+
+// clearHistory forgets the recorded steps, page keys they refer to are gone
+func clearHistory() {
+	h := activeHistory()
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.steps = nil
+	h.cursor = 0
+	h.size = 0
+}
@@ -0,0 +1,122 @@
+//go:build js && wasm
+
+package framework
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"syscall/js"
+)
+
+// historyOverlayID is the id of the time-travel overlay, it lives outside the app container
+const historyOverlayID = "stencil-history"
+
+const historyOverlayStyle = "position:fixed;bottom:1rem;right:1rem;z-index:2147483647;width:24rem;max-height:50vh;" +
+	"overflow:auto;background:#111827;color:#f9fafb;font:12px/1.5 monospace;padding:.75rem;" +
+	"border-radius:.5rem;box-shadow:0 10px 25px rgba(0,0,0,.35)"
+
+// renderHistoryOverlay creates, refreshes or removes the time-travel overlay
+func (a *app) renderHistoryOverlay() {
+	document := js.Global().Get("document")
+	overlay := document.Call("getElementById", historyOverlayID)
+
+	h := activeHistory()
+	if h == nil || !h.options.Overlay {
+		if overlay.Truthy() {
+			overlay.Call("remove")
+		}
+		return
+	}
+
+	if !overlay.Truthy() {
+		overlay = a.createHistoryOverlay(document)
+	}
+
+	h.mu.Lock()
+	steps := len(h.steps)
+	cursor := h.cursor
+	list := historyOverlayList(h.steps, h.cursor)
+	h.mu.Unlock()
+
+	overlay.Call("querySelector", "[data-history-status]").Set("textContent", fmt.Sprintf("History %d/%d", cursor, steps))
+	slider := overlay.Call("querySelector", "[data-history-slider]")
+	slider.Set("max", strconv.Itoa(steps))
+	// The slider is not moved under the pointer of a user dragging it
+	if !document.Get("activeElement").Equal(slider) {
+		slider.Set("value", strconv.Itoa(cursor))
+	}
+	overlay.Call("querySelector", "[data-history-list]").Set("innerHTML", list)
+}
+
+// createHistoryOverlay adds the overlay to the page and wires its controls
+func (a *app) createHistoryOverlay(document js.Value) js.Value {
+	overlay := document.Call("createElement", "div")
+	overlay.Set("id", historyOverlayID)
+	overlay.Get("style").Set("cssText", historyOverlayStyle)
+	overlay.Set("innerHTML", `<div style="display:flex;gap:.5rem;align-items:center;margin-bottom:.5rem">`+
+		`<strong data-history-status style="flex:1"></strong>`+
+		`<button type="button" data-history-action="undo">↶</button>`+
+		`<button type="button" data-history-action="redo">↷</button></div>`+
+		`<input type="range" min="0" step="1" data-history-slider style="width:100%">`+
+		`<ol data-history-list style="margin:.5rem 0 0;padding-left:1.5rem"></ol>`)
+
+	if a.historyFunc.IsUndefined() {
+		a.historyFunc = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			target := args[0].Get("target")
+			switch {
+			case args[0].Get("type").String() == "input" && target.Call("hasAttribute", "data-history-slider").Bool():
+				step, err := strconv.Atoi(target.Get("value").String())
+				if err == nil {
+					travelTo(step)
+				}
+			case args[0].Get("type").String() == "click":
+				switch target.Call("getAttribute", "data-history-action").String() {
+				case "undo":
+					Undo()
+				case "redo":
+					Redo()
+				}
+			}
+			return nil
+		})
+	}
+	overlay.Call("addEventListener", "input", a.historyFunc)
+	overlay.Call("addEventListener", "click", a.historyFunc)
+
+	document.Get("body").Call("appendChild", overlay)
+	return overlay
+}
+
+// historyOverlayList renders the recorded steps, the undone ones are dimmed
+func historyOverlayList(steps []historyStep, cursor int) string {
+	var b strings.Builder
+	for i, step := range steps {
+		style := ""
+		if i >= cursor {
+			style = ` style="opacity:.45"`
+		}
+		b.WriteString(`<li` + style + `>`)
+		for _, e := range step {
+			cause := ""
+			if e.Cause != "" {
+				cause = " <em>(" + html.EscapeString(e.Cause) + ")</em>"
+			}
+			b.WriteString(`<div>` + html.EscapeString(keyLabel(e.Key)) + `: ` +
+				html.EscapeString(historyValue(e.Old)) + ` → ` + html.EscapeString(historyValue(e.New)) +
+				cause + `</div>`)
+		}
+		b.WriteString(`</li>`)
+	}
+	return b.String()
+}
+
+// historyValue formats a state value for the overlay
+func historyValue(value interface{}) string {
+	text := fmt.Sprintf("%#v", value)
+	if len(text) > 60 {
+		text = text[:57] + "..."
+	}
+	return text
+}
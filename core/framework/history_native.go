@@ -0,0 +1,6 @@
+//go:build !(js && wasm)
+
+package framework
+
+// renderHistoryOverlay does nothing natively, there is no page to draw the overlay on
+func (a *app) renderHistoryOverlay() {}
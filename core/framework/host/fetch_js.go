@@ -0,0 +1,95 @@
+//go:build js && wasm
+
+package host
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+	"time"
+)
+
+// Fetch sends a request with the fetch() API of the browser
+func Fetch(request Request) (*Response, error) {
+	options := js.Global().Get("Object").New()
+	options.Set("method", request.Method)
+
+	headers := js.Global().Get("Object").New()
+	for key, value := range request.Headers {
+		headers.Set(key, value)
+	}
+	options.Set("headers", headers)
+
+	if request.Body != nil {
+		options.Set("body", string(request.Body))
+	}
+
+	// A timed out request is aborted, where supported, so that its promise settles right away
+	var controller js.Value
+	if abortController := js.Global().Get("AbortController"); abortController.Truthy() {
+		controller = abortController.New()
+		options.Set("signal", controller.Get("signal"))
+	}
+
+	type result struct {
+		response *Response
+		err      error
+	}
+	done := make(chan result, 1)
+
+	var onResponse, onText, onError, onSettled js.Func
+
+	response := &Response{Headers: make(map[string]string)}
+	onText = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		response.Body = []byte(args[0].String())
+		done <- result{response: response}
+		return nil
+	})
+	onResponse = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		res := args[0]
+		response.StatusCode = res.Get("status").Int()
+		collect := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			response.Headers[args[1].String()] = args[0].String()
+			return nil
+		})
+		res.Get("headers").Call("forEach", collect)
+		collect.Release()
+		return res.Call("text").Call("then", onText)
+	})
+	onError = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		message := "request failed"
+		if len(args) > 0 {
+			message = args[0].Call("toString").String()
+		}
+		done <- result{err: errors.New(message)}
+		return nil
+	})
+
+	// The callbacks are released once the promise settles, whether or not the caller timed out
+	onSettled = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		onResponse.Release()
+		onText.Release()
+		onError.Release()
+		onSettled.Release()
+		return nil
+	})
+
+	js.Global().Call("fetch", request.URL, options).
+		Call("then", onResponse).
+		Call("catch", onError).
+		Call("finally", onSettled)
+
+	var timeout <-chan time.Time
+	if request.Timeout > 0 {
+		timeout = time.After(request.Timeout)
+	}
+	select {
+	case r := <-done:
+		return r.response, r.err
+	case <-timeout:
+		if controller.Truthy() {
+			controller.Call("abort")
+		}
+		return nil, fmt.Errorf("request timed out after %s", request.Timeout)
+	}
+}
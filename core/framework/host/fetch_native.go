@@ -0,0 +1,46 @@
+//go:build !(js && wasm)
+
+package host
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// Fetch sends a request with net/http, outside the browser
+func Fetch(request Request) (*Response, error) {
+	var body io.Reader
+	if request.Body != nil {
+		body = bytes.NewReader(request.Body)
+	}
+	req, err := http.NewRequest(request.Method, request.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: request.Timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{
+		StatusCode: res.StatusCode,
+		Headers:    make(map[string]string, len(res.Header)),
+		Body:       data,
+	}
+	for key := range res.Header {
+		response.Headers[key] = res.Header.Get(key)
+	}
+	return response, nil
+}
@@ -0,0 +1,147 @@
+// Package host describes the environment a Stencil application runs in: the
+// container it renders into, the location and history of the page, the
+// listeners it registers and the HTTP requests it sends.
+//
+// The framework reaches the browser only through these interfaces, so the
+// routing, state, rendering and HTTP logic also run natively against an
+// in-memory host, for server rendering, tests and fuzzing. The browser host
+// is framework.BrowserHost, the in-memory one framework.NewMemoryHost.
+package host
+
+import (
+	"net/url"
+	"time"
+)
+
+// Host is the environment of an application
+type Host interface {
+	// Container returns the element with the given id, that the application renders into
+	Container(id string) (Container, error)
+
+	// Location returns the path and query string shown in the address bar, such as /products?page=2
+	Location() string
+	// PushHistory adds a location to the session history and shows it in the address bar, without reloading
+	PushHistory(location string)
+
+	// Listen calls fn for the window events of the given type: "popstate" after
+	// a back or forward navigation, "beforeunload" before the page is closed or
+	// reloaded, where PreventDefault asks the user to confirm leaving
+	Listen(eventType string, fn func(Event)) (remove func())
+	// RequestFrame calls fn once, before the next repaint
+	RequestFrame(fn func())
+
+	Fetcher
+}
+
+// Container is the element an application renders into
+type Container interface {
+	// Render updates the content of the container to match html, touching only the nodes that changed
+	Render(html string)
+	// Adopt resumes content rendered ahead of time, by a server or a static export:
+	// matching content is kept as is. Otherwise Adopt renders html and returns a
+	// description of the first difference.
+	Adopt(html string) (mismatch string)
+	// Listen calls fn for the events fired inside the container: click, change,
+	// input and submit, and the events named by the data-on<event> attributes
+	// of the rendered content
+	Listen(fn func(Event)) (remove func())
+	// Query returns the elements of the container matching a CSS selector, in document order
+	Query(selector string) []Element
+	// Contains reports whether el is inside the container
+	Contains(el Element) bool
+	// Focused returns the focused element, or nil
+	Focused() Element
+}
+
+// Element is an element of a container
+type Element interface {
+	// Tag returns the lowercase tag name
+	Tag() string
+	// Attr returns the value of an attribute and whether it is present
+	Attr(name string) (string, bool)
+	// Closest returns the element or its closest ancestor matching a CSS selector, or nil
+	Closest(selector string) Element
+	// Same reports whether other is the same element
+	Same(other Element) bool
+	// Text returns the text content
+	Text() string
+	// HTML returns the outer HTML
+	HTML() string
+
+	// Value returns the value of an input, a textarea or a select
+	Value() string
+	SetValue(value string)
+	// Checked returns whether a checkbox or a radio is checked
+	Checked() bool
+	SetChecked(checked bool)
+	// Selected returns the values of the selected options of a select
+	Selected() []string
+	SetSelected(values []string)
+
+	// Form returns the form the element belongs to, or nil
+	Form() Element
+	// FormValues returns the values a form element would submit
+	FormValues() url.Values
+	// Dataset returns the data-* attributes keyed like element.dataset: data-user-id is userId
+	Dataset() map[string]string
+}
+
+// Event is a DOM event
+type Event interface {
+	// Type returns the event type, such as "click" or "popstate"
+	Type() string
+	// Target returns the element the event was fired on, or nil for window events.
+	// Events fired on text nodes target their parent element.
+	Target() Element
+	// Key returns the key of a keyboard event
+	Key() string
+	// Modifiers returns the modifier keys held during the event
+	Modifiers() Modifiers
+	// Button returns the mouse button of a click, 0 for the main button
+	Button() int
+
+	PreventDefault()
+	StopPropagation()
+	DefaultPrevented() bool
+}
+
+// Modifiers reports the modifier keys held when an event fired
+type Modifiers struct {
+	Shift bool
+	Ctrl  bool
+	Alt   bool
+	Meta  bool
+}
+
+// Request is an HTTP request sent through a Fetcher
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+	// Timeout bounds the whole request, zero means no timeout
+	Timeout time.Duration
+}
+
+// Response is the response to a Request
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// Fetcher sends HTTP requests
+type Fetcher interface {
+	Fetch(request Request) (*Response, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher, for example to stub an API in tests
+type FetcherFunc func(request Request) (*Response, error)
+
+// Fetch calls f(request)
+func (f FetcherFunc) Fetch(request Request) (*Response, error) {
+	return f(request)
+}
+
+// DefaultFetcher sends requests with fetch() in the browser and net/http natively
+var DefaultFetcher Fetcher = FetcherFunc(Fetch)
@@ -0,0 +1,333 @@
+//go:build js && wasm
+
+package framework
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"syscall/js"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// BrowserHost returns the host of applications running in the browser
+func BrowserHost() host.Host {
+	return browserHost{}
+}
+
+// browserHost drives the window of the page through syscall/js
+type browserHost struct{}
+
+func (browserHost) Container(id string) (host.Container, error) {
+	el := js.Global().Get("document").Call("getElementById", id)
+	if el.IsNull() {
+		return nil, fmt.Errorf("Element with ID '%s' not found", id)
+	}
+	return &browserContainer{el: el, listeners: make(map[string]js.Func)}, nil
+}
+
+func (browserHost) Location() string {
+	location := js.Global().Get("location")
+	return location.Get("pathname").String() + location.Get("search").String()
+}
+
+func (browserHost) PushHistory(path string) {
+	js.Global().Get("history").Call("pushState", js.Null(), "", path)
+}
+
+func (browserHost) Listen(eventType string, fn func(host.Event)) (remove func()) {
+	listener := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		event := &jsEvent{event: args[0]}
+		fn(event)
+		// Browsers only show the leave confirmation when returnValue is set
+		if eventType == "beforeunload" && event.DefaultPrevented() {
+			args[0].Set("returnValue", "")
+		}
+		return nil
+	})
+	js.Global().Call("addEventListener", eventType, listener)
+	return func() {
+		js.Global().Call("removeEventListener", eventType, listener)
+		listener.Release()
+	}
+}
+
+func (browserHost) RequestFrame(fn func()) {
+	var callback js.Func
+	callback = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		callback.Release()
+		fn()
+		return nil
+	})
+	if raf := js.Global().Get("requestAnimationFrame"); raf.Truthy() {
+		js.Global().Call("requestAnimationFrame", callback)
+	} else {
+		// Environments without requestAnimationFrame fall back to a task
+		js.Global().Call("setTimeout", callback, 0)
+	}
+}
+
+func (browserHost) Fetch(request host.Request) (*host.Response, error) {
+	return host.Fetch(request)
+}
+
+// defaultEvents are delegated from startup, other events are delegated as
+// soon as a rendered element uses them
+var defaultEvents = []string{"click", "change", "input", "submit"}
+
+// browserContainer is a DOM element patched from the rendered HTML.
+// Events are delegated: a single listener per event type on the container.
+type browserContainer struct {
+	el        js.Value
+	listeners map[string]js.Func
+	handlers  []*func(host.Event)
+}
+
+// Render parses the HTML into a virtual tree and diffs it against the live DOM
+// so that only the nodes which actually changed are touched
+func (c *browserContainer) Render(html string) {
+	nodes := parseHTML(html)
+	patchChildren(c.el, nodes, "")
+	c.delegateAll(nodes)
+}
+
+func (c *browserContainer) Adopt(html string) string {
+	nodes := parseHTML(html)
+	mismatch := hydrationMismatch(c.el, nodes, "#"+c.el.Get("id").String())
+	if mismatch != "" {
+		patchChildren(c.el, nodes, "")
+	}
+	c.delegateAll(nodes)
+	return mismatch
+}
+
+func (c *browserContainer) Listen(fn func(host.Event)) (remove func()) {
+	handler := &fn
+	c.handlers = append(c.handlers, handler)
+	for _, eventType := range defaultEvents {
+		c.delegate(eventType)
+	}
+	return func() {
+		for i, h := range c.handlers {
+			if h == handler {
+				c.handlers = append(c.handlers[:i], c.handlers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (c *browserContainer) Query(selector string) []host.Element {
+	list := c.el.Call("querySelectorAll", selector)
+	elements := make([]host.Element, list.Length())
+	for i := range elements {
+		elements[i] = jsElement{list.Index(i)}
+	}
+	return elements
+}
+
+func (c *browserContainer) Contains(el host.Element) bool {
+	e, ok := el.(jsElement)
+	return ok && c.el.Call("contains", e.v).Bool()
+}
+
+func (c *browserContainer) Focused() host.Element {
+	focused := js.Global().Get("document").Get("activeElement")
+	if !focused.Truthy() {
+		return nil
+	}
+	return jsElement{focused}
+}
+
+// delegateAll makes sure a delegated listener exists for every event type used by the rendered nodes
+func (c *browserContainer) delegateAll(nodes []*vnode) {
+	for _, eventType := range collectEventTypes(nodes, nil) {
+		c.delegate(eventType)
+	}
+}
+
+// delegate registers the delegated listener for eventType once
+func (c *browserContainer) delegate(eventType string) {
+	if _, exists := c.listeners[eventType]; exists {
+		return
+	}
+
+	listener := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		event := &jsEvent{event: args[0]}
+		for _, handler := range c.handlers {
+			(*handler)(event)
+		}
+		return nil
+	})
+	c.listeners[eventType] = listener
+
+	// Non bubbling events only reach the container during the capture phase
+	c.el.Call("addEventListener", eventType, listener, nonBubblingEvents[eventType])
+}
+
+// jsElement is a host.Element backed by a DOM element
+type jsElement struct {
+	v js.Value
+}
+
+// element wraps v, or returns nil when v is null or undefined
+func element(v js.Value) host.Element {
+	if !v.Truthy() {
+		return nil
+	}
+	return jsElement{v}
+}
+
+func (e jsElement) Tag() string {
+	return strings.ToLower(e.v.Get("nodeName").String())
+}
+
+func (e jsElement) Attr(name string) (string, bool) {
+	value := e.v.Call("getAttribute", name)
+	if value.IsNull() {
+		return "", false
+	}
+	return value.String(), true
+}
+
+func (e jsElement) Closest(selector string) host.Element {
+	return element(e.v.Call("closest", selector))
+}
+
+func (e jsElement) Same(other host.Element) bool {
+	o, ok := other.(jsElement)
+	return ok && e.v.Equal(o.v)
+}
+
+func (e jsElement) Text() string { return e.v.Get("textContent").String() }
+func (e jsElement) HTML() string { return e.v.Get("outerHTML").String() }
+
+func (e jsElement) Value() string {
+	value := e.v.Get("value")
+	if value.Type() != js.TypeString {
+		return ""
+	}
+	return value.String()
+}
+
+func (e jsElement) SetValue(value string)   { e.v.Set("value", value) }
+func (e jsElement) Checked() bool           { return e.v.Get("checked").Truthy() }
+func (e jsElement) SetChecked(checked bool) { e.v.Set("checked", checked) }
+
+func (e jsElement) Selected() []string {
+	selected := []string{}
+	options := e.v.Get("selectedOptions")
+	if !options.Truthy() {
+		return selected
+	}
+	for i := 0; i < options.Length(); i++ {
+		selected = append(selected, options.Index(i).Get("value").String())
+	}
+	return selected
+}
+
+func (e jsElement) SetSelected(values []string) {
+	options := e.v.Get("options")
+	if !options.Truthy() {
+		return
+	}
+	for i := 0; i < options.Length(); i++ {
+		option := options.Index(i)
+		selected := containsString(values, option.Get("value").String())
+		if option.Get("selected").Bool() != selected {
+			option.Set("selected", selected)
+		}
+	}
+}
+
+func (e jsElement) Form() host.Element {
+	if form := e.v.Get("form"); form.Truthy() {
+		return jsElement{form}
+	}
+	return element(e.v.Call("closest", "form"))
+}
+
+func (e jsElement) FormValues() url.Values {
+	values := url.Values{}
+	if e.v.Get("nodeName").String() != "FORM" {
+		return values
+	}
+
+	entries := js.Global().Get("FormData").New(e.v).Call("entries")
+	for {
+		next := entries.Call("next")
+		if next.Get("done").Bool() {
+			break
+		}
+		entry := next.Get("value")
+		// File entries are not represented in url.Values
+		if value := entry.Index(1); value.Type() == js.TypeString {
+			values.Add(entry.Index(0).String(), value.String())
+		}
+	}
+	return values
+}
+
+func (e jsElement) Dataset() map[string]string {
+	dataset := make(map[string]string)
+	keys := js.Global().Get("Object").Call("keys", e.v.Get("dataset"))
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		dataset[key] = e.v.Get("dataset").Get(key).String()
+	}
+	return dataset
+}
+
+// jsEvent is a host.Event backed by a browser event
+type jsEvent struct {
+	event js.Value
+}
+
+func (e *jsEvent) Type() string { return e.event.Get("type").String() }
+
+func (e *jsEvent) Target() host.Element {
+	target := e.event.Get("target")
+	if !target.Truthy() {
+		return nil
+	}
+	nodeType := target.Get("nodeType")
+	if nodeType.Type() != js.TypeNumber {
+		// The window is not a node
+		return nil
+	}
+	// Events fired on text nodes are handled by their parent element
+	if nodeType.Int() != domElementNode {
+		return element(target.Get("parentElement"))
+	}
+	return jsElement{target}
+}
+
+func (e *jsEvent) Key() string {
+	key := e.event.Get("key")
+	if key.Type() != js.TypeString {
+		return ""
+	}
+	return key.String()
+}
+
+func (e *jsEvent) Modifiers() Modifiers {
+	return Modifiers{
+		Shift: e.event.Get("shiftKey").Truthy(),
+		Ctrl:  e.event.Get("ctrlKey").Truthy(),
+		Alt:   e.event.Get("altKey").Truthy(),
+		Meta:  e.event.Get("metaKey").Truthy(),
+	}
+}
+
+func (e *jsEvent) Button() int {
+	button := e.event.Get("button")
+	if button.Type() != js.TypeNumber {
+		return 0
+	}
+	return button.Int()
+}
+
+func (e *jsEvent) PreventDefault()        { e.event.Call("preventDefault") }
+func (e *jsEvent) StopPropagation()       { e.event.Call("stopPropagation") }
+func (e *jsEvent) DefaultPrevented() bool { return e.event.Get("defaultPrevented").Bool() }
@@ -0,0 +1,493 @@
+package framework
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// MemoryHost is a host that keeps the page in memory: containers are
+// documents parsed from the rendered HTML, the history is a list of paths and
+// frames run when asked to. It lets the framework run natively, for tests,
+// fuzzing and tools, see Headless.
+type MemoryHost struct {
+	// Fetcher answers the requests sent through the host, they fail when it is nil
+	Fetcher host.Fetcher
+
+	history   []string
+	index     int
+	listeners map[string][]*func(host.Event)
+
+	containers map[string]*memoryContainer
+
+	mu     sync.Mutex // frames may be requested by goroutines
+	frames []func()
+}
+
+// NewMemoryHost creates a host whose location is path, which may have a query string
+func NewMemoryHost(location string) *MemoryHost {
+	if !strings.HasPrefix(location, "/") {
+		location = "/" + location
+	}
+	return &MemoryHost{
+		history:    []string{location},
+		listeners:  make(map[string][]*func(host.Event)),
+		containers: make(map[string]*memoryContainer),
+	}
+}
+
+// SetContent replaces the content of the container with the given id, for
+// example with a server render that Adopt then resumes
+func (m *MemoryHost) SetContent(id, html string) {
+	m.container(id).load(html)
+}
+
+// Container returns the container with the given id, created empty on first use
+func (m *MemoryHost) Container(id string) (host.Container, error) {
+	return m.container(id), nil
+}
+
+func (m *MemoryHost) container(id string) *memoryContainer {
+	c, exists := m.containers[id]
+	if !exists {
+		c = &memoryContainer{}
+		c.load("")
+		m.containers[id] = c
+	}
+	return c
+}
+
+// Location returns the current path and query string
+func (m *MemoryHost) Location() string {
+	return m.history[m.index]
+}
+
+// PushHistory makes location the current location, dropping the forward history
+func (m *MemoryHost) PushHistory(location string) {
+	m.history = append(m.history[:m.index+1], location)
+	m.index++
+}
+
+// Back goes back one step in the history and fires popstate, like the back button.
+// It reports false when there is no previous location.
+func (m *MemoryHost) Back() bool {
+	if m.index == 0 {
+		return false
+	}
+	m.index--
+	m.fire(&memoryEvent{eventType: "popstate"})
+	return true
+}
+
+// Forward goes forward one step in the history and fires popstate.
+// It reports false when there is no next location.
+func (m *MemoryHost) Forward() bool {
+	if m.index == len(m.history)-1 {
+		return false
+	}
+	m.index++
+	m.fire(&memoryEvent{eventType: "popstate"})
+	return true
+}
+
+// Unload fires beforeunload, like closing the tab, and reports whether the page lets the user leave
+func (m *MemoryHost) Unload() bool {
+	event := &memoryEvent{eventType: "beforeunload"}
+	m.fire(event)
+	return !event.prevented
+}
+
+// Listen registers a listener for window events
+func (m *MemoryHost) Listen(eventType string, fn func(host.Event)) (remove func()) {
+	handler := &fn
+	m.listeners[eventType] = append(m.listeners[eventType], handler)
+	return func() {
+		m.listeners[eventType] = removeHandler(m.listeners[eventType], handler)
+	}
+}
+
+func (m *MemoryHost) fire(event *memoryEvent) {
+	for _, handler := range append([]*func(host.Event){}, m.listeners[event.eventType]...) {
+		(*handler)(event)
+	}
+}
+
+// RequestFrame queues fn until RunFrames
+func (m *MemoryHost) RequestFrame(fn func()) {
+	m.mu.Lock()
+	m.frames = append(m.frames, fn)
+	m.mu.Unlock()
+}
+
+// RunFrames runs the queued frames, like the browser does before a repaint
+func (m *MemoryHost) RunFrames() {
+	m.mu.Lock()
+	frames := m.frames
+	m.frames = nil
+	m.mu.Unlock()
+	for _, fn := range frames {
+		fn()
+	}
+}
+
+// Fetch sends the request to the host's Fetcher
+func (m *MemoryHost) Fetch(request host.Request) (*host.Response, error) {
+	if m.Fetcher == nil {
+		return nil, errors.New("memory host: no Fetcher for " + request.Method + " " + request.URL)
+	}
+	return m.Fetcher.Fetch(request)
+}
+
+// removeHandler removes handler from a list of listeners
+func removeHandler(handlers []*func(host.Event), handler *func(host.Event)) []*func(host.Event) {
+	for i, h := range handlers {
+		if h == handler {
+			return append(handlers[:i:i], handlers[i+1:]...)
+		}
+	}
+	return handlers
+}
+
+// memoryContainer is an in-memory document
+type memoryContainer struct {
+	html     string // render the document was parsed from
+	nodes    []*vnode
+	parents  map[*vnode]*vnode
+	elements map[*vnode]*memoryElement
+	handlers []*func(host.Event)
+	focused  *memoryElement
+}
+
+// load replaces the document with the parsed HTML
+func (c *memoryContainer) load(html string) {
+	c.html = html
+	c.nodes = parseHTML(html)
+	c.parents = make(map[*vnode]*vnode)
+	c.elements = make(map[*vnode]*memoryElement)
+	c.focused = nil
+
+	var index func(parent *vnode, nodes []*vnode)
+	index = func(parent *vnode, nodes []*vnode) {
+		for _, n := range nodes {
+			if n.kind != elementNode {
+				continue
+			}
+			if parent != nil {
+				c.parents[n] = parent
+			}
+			c.elements[n] = newMemoryElement(c, n)
+			index(n, n.children)
+		}
+	}
+	index(nil, c.nodes)
+
+	// Selects without a selected option select their first option, like in a browser
+	for _, el := range c.elements {
+		if el.node.tag == "select" && len(el.Selected()) == 0 && !isMultiple(el) {
+			if options := el.options(); len(options) > 0 {
+				options[0].checked = true
+			}
+		}
+	}
+}
+
+// Render replaces the document when the HTML changed. Unlike a browser, the
+// properties changed since the previous render, such as typed values, are not
+// kept on the elements that did not change.
+func (c *memoryContainer) Render(html string) {
+	if html != c.html {
+		c.load(html)
+	}
+}
+
+func (c *memoryContainer) Adopt(html string) string {
+	if renderNodes(c.nodes) != renderNodes(parseHTML(html)) {
+		c.load(html)
+		return "the content differs from the render"
+	}
+	c.html = html
+	return ""
+}
+
+func (c *memoryContainer) Listen(fn func(host.Event)) (remove func()) {
+	handler := &fn
+	c.handlers = append(c.handlers, handler)
+	return func() {
+		c.handlers = removeHandler(c.handlers, handler)
+	}
+}
+
+// dispatch fires event to the listeners of the container
+func (c *memoryContainer) dispatch(event *memoryEvent) {
+	if event.eventType == "focus" {
+		c.focused = event.target
+	}
+	for _, handler := range append([]*func(host.Event){}, c.handlers...) {
+		(*handler)(event)
+	}
+}
+
+func (c *memoryContainer) Query(selector string) []host.Element {
+	sel, err := parseSelector(selector)
+	if err != nil {
+		return nil
+	}
+	var found []host.Element
+	c.walk(nil, func(n *vnode) {
+		if sel.match(n, c.ancestors(n)) {
+			found = append(found, c.elements[n])
+		}
+	})
+	return found
+}
+
+func (c *memoryContainer) Contains(el host.Element) bool {
+	e, ok := el.(*memoryElement)
+	return ok && e.container == c && c.elements[e.node] == e
+}
+
+func (c *memoryContainer) Focused() host.Element {
+	if c.focused == nil || !c.Contains(c.focused) {
+		return nil
+	}
+	return c.focused
+}
+
+// ancestors lists the ancestors of n, the closest first
+func (c *memoryContainer) ancestors(n *vnode) []*vnode {
+	var list []*vnode
+	for p := c.parents[n]; p != nil; p = c.parents[p] {
+		list = append(list, p)
+	}
+	return list
+}
+
+// walk calls fn for every element below root, or in the whole document when root is nil
+func (c *memoryContainer) walk(root *vnode, fn func(*vnode)) {
+	nodes := c.nodes
+	if root != nil {
+		nodes = root.children
+	}
+	var walk func(nodes []*vnode)
+	walk = func(nodes []*vnode) {
+		for _, n := range nodes {
+			if n.kind == elementNode {
+				fn(n)
+				walk(n.children)
+			}
+		}
+	}
+	walk(nodes)
+}
+
+// memoryElement is an element of a memoryContainer, with the properties a browser keeps on the DOM
+type memoryElement struct {
+	container *memoryContainer
+	node      *vnode
+	value     string
+	checked   bool // checkboxes, radios and selected options
+}
+
+func newMemoryElement(c *memoryContainer, n *vnode) *memoryElement {
+	el := &memoryElement{container: c, node: n}
+	switch n.tag {
+	case "textarea":
+		el.value = n.textContent()
+	case "option":
+		if value, exists := n.getAttr("value"); exists {
+			el.value = value
+		} else {
+			el.value = strings.TrimSpace(n.textContent())
+		}
+		_, el.checked = n.getAttr("selected")
+	default:
+		value, exists := n.getAttr("value")
+		if t := controlType(el); !exists && (t == "checkbox" || t == "radio") {
+			value = "on"
+		}
+		el.value = value
+		_, el.checked = n.getAttr("checked")
+	}
+	return el
+}
+
+func (e *memoryElement) Tag() string { return e.node.tag }
+
+func (e *memoryElement) Attr(name string) (string, bool) {
+	return e.node.getAttr(strings.ToLower(name))
+}
+
+func (e *memoryElement) Closest(selector string) host.Element {
+	sel, err := parseSelector(selector)
+	if err != nil {
+		return nil
+	}
+	for n := e.node; n != nil; n = e.container.parents[n] {
+		if sel.match(n, e.container.ancestors(n)) {
+			return e.container.elements[n]
+		}
+	}
+	return nil
+}
+
+func (e *memoryElement) Same(other host.Element) bool {
+	o, ok := other.(*memoryElement)
+	return ok && o == e
+}
+
+func (e *memoryElement) Text() string { return e.node.textContent() }
+func (e *memoryElement) HTML() string { return renderNodes([]*vnode{e.node}) }
+
+func (e *memoryElement) Value() string {
+	if e.node.tag == "select" {
+		if selected := e.Selected(); len(selected) > 0 {
+			return selected[0]
+		}
+		return ""
+	}
+	return e.value
+}
+
+func (e *memoryElement) SetValue(value string) {
+	if e.node.tag == "select" {
+		e.SetSelected([]string{value})
+		return
+	}
+	e.value = value
+}
+
+func (e *memoryElement) Checked() bool { return e.checked }
+
+// SetChecked checks or unchecks a checkbox or a radio, checking a radio
+// unchecks the other radios of its group
+func (e *memoryElement) SetChecked(checked bool) {
+	if checked && controlType(e) == "radio" {
+		name, _ := e.Attr("name")
+		var scope *vnode
+		if form := e.Form(); form != nil {
+			scope = form.(*memoryElement).node
+		}
+		e.container.walk(scope, func(n *vnode) {
+			other := e.container.elements[n]
+			if otherName, _ := n.getAttr("name"); n.tag == "input" && otherName == name && controlType(other) == "radio" {
+				other.checked = false
+			}
+		})
+	}
+	e.checked = checked
+}
+
+func (e *memoryElement) Selected() []string {
+	selected := []string{}
+	for _, option := range e.options() {
+		if option.checked {
+			selected = append(selected, option.value)
+		}
+	}
+	return selected
+}
+
+func (e *memoryElement) SetSelected(values []string) {
+	for _, option := range e.options() {
+		option.checked = containsString(values, option.value)
+	}
+}
+
+// options returns the options of a select
+func (e *memoryElement) options() []*memoryElement {
+	var options []*memoryElement
+	e.container.walk(e.node, func(n *vnode) {
+		if n.tag == "option" {
+			options = append(options, e.container.elements[n])
+		}
+	})
+	return options
+}
+
+func (e *memoryElement) Form() host.Element {
+	for n := e.container.parents[e.node]; n != nil; n = e.container.parents[n] {
+		if n.tag == "form" {
+			return e.container.elements[n]
+		}
+	}
+	return nil
+}
+
+// FormValues collects the values a form would submit
+func (e *memoryElement) FormValues() url.Values {
+	values := url.Values{}
+	if e.node.tag != "form" {
+		return values
+	}
+	e.container.walk(e.node, func(n *vnode) {
+		name, named := n.getAttr("name")
+		if _, disabled := n.getAttr("disabled"); !named || disabled {
+			return
+		}
+		control := e.container.elements[n]
+		switch n.tag {
+		case "input":
+			switch controlType(control) {
+			case "submit", "button", "reset", "image", "file":
+				return
+			case "checkbox", "radio":
+				if !control.checked {
+					return
+				}
+			}
+			values.Add(name, control.Value())
+		case "textarea":
+			values.Add(name, control.Value())
+		case "select":
+			for _, value := range control.Selected() {
+				values.Add(name, value)
+			}
+		}
+	})
+	return values
+}
+
+func (e *memoryElement) Dataset() map[string]string {
+	data := make(map[string]string)
+	for _, attr := range e.node.attrs {
+		if !strings.HasPrefix(attr.name, "data-") {
+			continue
+		}
+		parts := strings.Split(attr.name[len("data-"):], "-")
+		for i := 1; i < len(parts); i++ {
+			if parts[i] != "" {
+				parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+			}
+		}
+		data[strings.Join(parts, "")] = attr.value
+	}
+	return data
+}
+
+// memoryEvent is an event fired on a MemoryHost
+type memoryEvent struct {
+	eventType string
+	target    *memoryElement
+	key       string
+	modifiers Modifiers
+	prevented bool
+}
+
+func (e *memoryEvent) Type() string { return e.eventType }
+
+func (e *memoryEvent) Target() host.Element {
+	if e.target == nil {
+		return nil
+	}
+	return e.target
+}
+
+func (e *memoryEvent) Key() string            { return e.key }
+func (e *memoryEvent) Modifiers() Modifiers   { return e.modifiers }
+func (e *memoryEvent) Button() int            { return 0 }
+func (e *memoryEvent) PreventDefault()        { e.prevented = true }
+func (e *memoryEvent) StopPropagation()       {}
+func (e *memoryEvent) DefaultPrevented() bool { return e.prevented }
@@ -0,0 +1,111 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// stateScriptID is the id of the script element holding the pre-rendered state
+const stateScriptID = "stencil-state"
+
+// hydration is the page state serialized by a server or static render
+type hydration struct {
+	Path  string                     `json:"path"`
+	State map[string]json.RawMessage `json:"state"`
+}
+
+// pageState returns the initial state of the page being hydrated: the serialized
+// values, decoded to the type of the matching GetInitialState value when there is one
+func (h *hydration) pageState(initial map[string]interface{}) map[string]interface{} {
+	if h == nil {
+		return initial
+	}
+	state := make(map[string]interface{}, len(h.State))
+	for key, value := range initial {
+		state[key] = value
+	}
+	for key, raw := range h.State {
+		if value, ok := decodeLike(raw, initial[key]); ok {
+			state[key] = value
+		}
+	}
+	return state
+}
+
+// revive returns the initial state of a component with the serialized values of its keys
+func (h *hydration) revive(prefix string, initial map[string]interface{}) map[string]interface{} {
+	if h == nil {
+		return initial
+	}
+	state := make(map[string]interface{}, len(initial))
+	for key, value := range initial {
+		state[key] = value
+		if raw, exists := h.State[prefix+key]; exists {
+			if revived, ok := decodeLike(raw, value); ok {
+				state[key] = revived
+			}
+		}
+	}
+	return state
+}
+
+// decodeLike decodes a serialized value to the type of like.
+// Without a hint (like is nil) objects and arrays decode to maps and slices,
+// and numbers to json.Number which the typed state API converts losslessly.
+func decodeLike(raw json.RawMessage, like interface{}) (interface{}, bool) {
+	if like == nil {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		return value, true
+	}
+	target := reflect.New(reflect.TypeOf(like))
+	if err := json.Unmarshal(raw, target.Interface()); err != nil {
+		return nil, false
+	}
+	return target.Elem().Interface(), true
+}
+
+// Snapshot is a page rendered outside the browser, with the state it was rendered from
+type Snapshot struct {
+	Path   string
+	HTML   string
+	Status int   // 200, 404 when no route matches, or 500 when the page panicked
+	Err    error // the recovered panic when Status is 500
+	State  map[string]interface{}
+}
+
+// Document renders the snapshot into an HTML shell: the page goes into the
+// element with the id containerID and the state into a script element that
+// RunWithRouter reads to resume the page without rendering it again.
+// State values that cannot be encoded as JSON are left out, the browser then
+// starts them from GetInitialState.
+func (s Snapshot) Document(shell, containerID string) (string, error) {
+	document, err := injectContainer(shell, containerID, s.HTML)
+	if err != nil {
+		return "", err
+	}
+
+	serialized := hydration{Path: s.Path, State: make(map[string]json.RawMessage, len(s.State))}
+	for key, value := range s.State {
+		if raw, err := json.Marshal(value); err == nil {
+			serialized.State[key] = raw
+		}
+	}
+	// json.Marshal escapes <, > and &, so the state cannot close the script element
+	data, err := json.Marshal(serialized)
+	if err != nil {
+		return "", err
+	}
+	script := `<script type="application/json" id="` + stateScriptID + `">` + string(data) + `</script>`
+
+	if end := strings.LastIndex(strings.ToLower(document), "</body>"); end >= 0 {
+		return document[:end] + script + "\n" + document[end:], nil
+	}
+	return document + script, nil
+}
@@ -0,0 +1,74 @@
+//go:build js && wasm
+
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"syscall/js"
+)
+
+// readHydration reads and removes the state serialized by Snapshot.Document
+func readHydration() *hydration {
+	script := js.Global().Get("document").Call("getElementById", stateScriptID)
+	if !script.Truthy() {
+		return nil
+	}
+	script.Call("remove")
+
+	var h hydration
+	if err := json.Unmarshal([]byte(script.Get("textContent").String()), &h); err != nil {
+		warn("stencil: ignoring the pre-rendered state: %v", err)
+		return nil
+	}
+	return &h
+}
+
+// hydrationMismatch compares the live children of parent with the rendered
+// nodes and describes the first difference, or returns "" when they match
+func hydrationMismatch(parent js.Value, nodes []*vnode, where string) string {
+	live := parent.Get("childNodes")
+	if live.Length() != len(nodes) {
+		return fmt.Sprintf("%s: %d nodes rendered, %d in the page", where, len(nodes), live.Length())
+	}
+
+	for i, n := range nodes {
+		node := live.Index(i)
+		switch n.kind {
+		case textNode, commentNode:
+			want := domTextNode
+			if n.kind == commentNode {
+				want = domCommentNode
+			}
+			if node.Get("nodeType").Int() != want || node.Get("data").String() != n.text {
+				return fmt.Sprintf("%s: text %q rendered, %q in the page", where, n.text, node.Get("textContent").String())
+			}
+
+		case elementNode:
+			if node.Get("nodeType").Int() != domElementNode || strings.ToLower(node.Get("nodeName").String()) != n.tag {
+				return fmt.Sprintf("%s: <%s> rendered, %s in the page", where, n.tag, node.Get("nodeName").String())
+			}
+			path := fmt.Sprintf("%s > %s:nth-child(%d)", where, n.tag, i+1)
+
+			if node.Get("attributes").Length() != len(n.attrs) {
+				return fmt.Sprintf("%s: %d attributes rendered, %d in the page", path, len(n.attrs), node.Get("attributes").Length())
+			}
+			for _, attr := range n.attrs {
+				value := node.Call("getAttribute", attr.name)
+				if value.IsNull() || value.String() != attr.value {
+					return fmt.Sprintf("%s: attribute %s=%q rendered, %v in the page", path, attr.name, attr.value, value)
+				}
+			}
+
+			// Elements managed outside the framework may have changed since the render
+			if _, ignored := n.getAttr(ignoreAttr); ignored {
+				continue
+			}
+			if mismatch := hydrationMismatch(node, n.children, path); mismatch != "" {
+				return mismatch
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,289 @@
+package framework
+
+import (
+	"html"
+	"sort"
+	"strings"
+)
+
+// Attributes marking the root element of a layout and the slot holding its child content
+const (
+	layoutAttr = "data-stencil-layout"
+	slotAttr   = "data-stencil-slot"
+)
+
+// layoutKeyPrefix starts the app state keys of layout instances, see componentKeyPrefix
+const layoutKeyPrefix = "\x00layout:"
+
+// Layout wraps the pages under a path prefix, for example the header and the
+// navigation of a section. Render receives the child content, the page or a
+// nested layout, as slot and places it in its own markup.
+//
+// A layout is mounted once and kept, with its local state, for as long as
+// the user navigates between the routes under its prefix: only the slot is
+// rendered again. Its state lives in the app-wide store and is disposed of
+// when the user leaves the prefix. Components embedded by a layout belong to
+// the page and are created again on navigation, keep what must survive in the
+// layout state. Layouts may implement Mounter and Unmounter.
+type Layout interface {
+	Render(l *LayoutContext, slot string) string
+	HandleEvent(l *LayoutContext, eventName string, event Event)
+	GetInitialState() map[string]interface{}
+}
+
+// LayoutHandler represents a function that returns a Layout
+type LayoutHandler func() Layout
+
+// BaseLayout provides a base implementation that users can embed in their layouts
+type BaseLayout struct{}
+
+// GetInitialState provides default empty initial state
+func (l *BaseLayout) GetInitialState() map[string]interface{} {
+	return make(map[string]interface{})
+}
+
+// HandleEvent provides default empty event handling
+func (l *BaseLayout) HandleEvent(ctx *LayoutContext, eventName string, event Event) {
+	// Override this method in your layout to handle events
+}
+
+// Render provides a default render method showing the child content only
+func (l *BaseLayout) Render(ctx *LayoutContext, slot string) string {
+	return slot
+}
+
+// LayoutContext gives a layout instance access to its local state
+type LayoutContext struct {
+	pattern string // path the layout was registered for
+	path    string
+}
+
+// layoutRoute is a layout registered for a path prefix
+type layoutRoute struct {
+	pattern routePattern
+	handler LayoutHandler
+}
+
+// layoutMatch is a layout applying to the current path, prefix is the part of the path it matched
+type layoutMatch struct {
+	route  *layoutRoute
+	prefix string
+}
+
+// layoutInstance is a layout wrapping the current page
+type layoutInstance struct {
+	layout  Layout
+	pattern string // path the layout was registered for
+	ctx     *LayoutContext
+	mounted bool       // whether OnMount ran
+	failure *ErrorInfo // panic that replaced the layout with the error view
+}
+
+// Path returns the part of the current path the layout applies to,
+// such as /users/42 for a layout registered for /users/:id
+func (c *LayoutContext) Path() string {
+	return c.path
+}
+
+// Key returns the app state key backing a local state key,
+// it can be used with the typed state API: framework.NewAppKey[bool](c.Key("open"))
+func (c *LayoutContext) Key(key string) string {
+	return layoutKeyPrefix + c.id() + "\x00" + key
+}
+
+// id identifies the layout instance: layouts registered for different
+// patterns may match the same prefix, such as / and /:lang? on /
+func (c *LayoutContext) id() string {
+	return c.pattern + " " + c.path
+}
+
+// Get retrieves a value from the local state
+func (c *LayoutContext) Get(key string) interface{} {
+	value, _ := lookupState(appScope, c.Key(key))
+	return value
+}
+
+// GetString retrieves a local state value as string
+func (c *LayoutContext) GetString(key string) string {
+	value, _ := Lookup(NewAppKey[string](c.Key(key)))
+	return value
+}
+
+// GetInt retrieves a local state value as int
+func (c *LayoutContext) GetInt(key string) int {
+	value, _ := Lookup(NewAppKey[int](c.Key(key)))
+	return value
+}
+
+// GetBool retrieves a local state value as bool
+func (c *LayoutContext) GetBool(key string) bool {
+	value, _ := Lookup(NewAppKey[bool](c.Key(key)))
+	return value
+}
+
+// Set updates the local state and schedules a re-render
+func (c *LayoutContext) Set(key string, value interface{}) {
+	setScopedState(appScope, c.Key(key), value)
+}
+
+// RegisterLayout registers a layout for the routes under a path prefix:
+// /admin wraps /admin, /admin/users and so on, and / wraps every page,
+// including the 404 page. Layouts nest from the shortest prefix, and the
+// prefix may have parameters, such as /users/:id, in which case a new
+// instance is mounted when they change. Registering a prefix again replaces
+// its layout.
+func (r *Router) RegisterLayout(path string, handler LayoutHandler) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	layout := &layoutRoute{pattern: parseRoutePattern(path, nil), handler: handler}
+	for i, existing := range r.layouts {
+		if strings.Trim(existing.pattern.path, "/") == strings.Trim(path, "/") {
+			r.layouts[i] = layout
+			return
+		}
+	}
+	r.layouts = append(r.layouts, layout)
+	sort.SliceStable(r.layouts, func(i, j int) bool {
+		a, b := r.layouts[i].pattern, r.layouts[j].pattern
+		if len(a.segments) != len(b.segments) {
+			return len(a.segments) < len(b.segments)
+		}
+		return compareRoutes(a, b) < 0
+	})
+}
+
+// RegisterLayout registers a layout globally, see Router.RegisterLayout
+func RegisterLayout(path string, handler LayoutHandler) {
+	InitRouter().RegisterLayout(path, handler)
+}
+
+// layoutsFor returns the layouts applying to path, from the outermost.
+// When several prefixes of the same depth match, the most specific wins.
+func (r *Router) layoutsFor(path string) []layoutMatch {
+	var chain []layoutMatch
+	depth := -1
+	for _, layout := range r.layouts {
+		if len(layout.pattern.segments) == depth {
+			continue
+		}
+		if prefix, ok := layout.pattern.matchPrefix(path); ok {
+			chain = append(chain, layoutMatch{route: layout, prefix: prefix})
+			depth = len(layout.pattern.segments)
+		}
+	}
+	return chain
+}
+
+// mountLayouts sets up the layouts of path around the page being mounted.
+// The layouts the previous page shared with it are kept along with their
+// state, the others are unmounted and the new ones created. A failed layout
+// is created again, so that navigating away recovers from it.
+func (a *app) mountLayouts(path string) {
+	var chain []layoutMatch
+	if globalRouter != nil {
+		chain = globalRouter.layoutsFor(path)
+	}
+
+	kept := 0
+	for kept < len(chain) && kept < len(a.layouts) && a.layouts[kept].keeps(chain[kept]) {
+		kept++
+	}
+	a.unmountLayouts(kept)
+
+	for _, match := range chain[kept:] {
+		instance := &layoutInstance{
+			pattern: match.route.pattern.path,
+			ctx:     &LayoutContext{pattern: match.route.pattern.path, path: match.prefix},
+		}
+		var initial map[string]interface{}
+		instance.failure = protect(ErrorInfo{Phase: "mount", Layout: instance.pattern}, func() {
+			instance.layout = match.route.handler()
+			initial = instance.layout.GetInitialState()
+		})
+		a.state.seed(appScope, instance.ctx.Key(""), initial)
+		a.layouts = append(a.layouts, instance)
+	}
+}
+
+// keeps reports whether the instance can stay in place of the layout match
+func (l *layoutInstance) keeps(match layoutMatch) bool {
+	return l.failure == nil && l.pattern == match.route.pattern.path && l.ctx.path == match.prefix
+}
+
+// unmountLayouts runs the OnUnmount hook of the layouts from index from, the
+// innermost first, and disposes of their state
+func (a *app) unmountLayouts(from int) {
+	for i := len(a.layouts) - 1; i >= from; i-- {
+		instance := a.layouts[i]
+		if unmounter, ok := instance.layout.(Unmounter); ok && instance.mounted && instance.failure == nil {
+			protect(ErrorInfo{Phase: "unmount", Layout: instance.pattern}, unmounter.OnUnmount)
+		}
+		a.state.deletePrefix(appScope, instance.ctx.Key(""))
+	}
+	a.layouts = a.layouts[:from]
+}
+
+// renderLayouts wraps content, the page or the error view, in the current layouts
+func (a *app) renderLayouts(content string) string {
+	for i := len(a.layouts) - 1; i >= 0; i-- {
+		content = a.layouts[i].render(content)
+	}
+	return content
+}
+
+// render renders the layout around content.
+// A failing layout is replaced with the error view, content included.
+func (l *layoutInstance) render(content string) string {
+	slot := `<div ` + slotAttr + ` style="display:contents">` + content + `</div>`
+	var body string
+	if l.failure == nil {
+		l.failure = protect(ErrorInfo{Phase: "render", Layout: l.pattern}, func() {
+			body = l.layout.Render(l.ctx, slot)
+		})
+	}
+	if l.failure != nil {
+		body = renderErrorView(*l.failure)
+	}
+	return `<div ` + layoutAttr + `="` + html.EscapeString(l.ctx.id()) + `" style="display:contents">` + body + `</div>`
+}
+
+// afterLayoutsRender runs OnMount on the layouts rendered for the first time
+func (a *app) afterLayoutsRender() {
+	failed := false
+	for _, instance := range a.layouts {
+		if instance.mounted || instance.failure != nil {
+			continue
+		}
+		instance.mounted = true
+		if mounter, ok := instance.layout.(Mounter); ok {
+			instance.failure = protect(ErrorInfo{Phase: "mount", Layout: instance.pattern}, mounter.OnMount)
+			failed = failed || instance.failure != nil
+		}
+	}
+	if failed {
+		a.invalidate()
+	}
+}
+
+// handleLayoutEvent routes an event fired inside a layout's own markup to
+// its instance, id is the value of its layout attribute
+func (a *app) handleLayoutEvent(id, eventName string, event Event) {
+	var instance *layoutInstance
+	for _, l := range a.layouts {
+		if l.ctx.id() == id {
+			instance = l
+		}
+	}
+	if instance == nil || instance.failure != nil {
+		return
+	}
+	a.batch(func() {
+		a.state.setCause(instance.ctx.path + ":" + eventName)
+		defer a.state.setCause("")
+		instance.failure = protect(ErrorInfo{Phase: "event", Layout: instance.pattern, Event: eventName}, func() {
+			instance.layout.HandleEvent(instance.ctx, eventName, event)
+		})
+		a.invalidate()
+	})
+}
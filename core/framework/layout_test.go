@@ -0,0 +1,88 @@
+package framework
+
+import (
+	"fmt"
+	"testing"
+)
+
+// counterLayout counts the clicks on its own button
+type counterLayout struct {
+	BaseLayout
+	name string
+}
+
+func (l *counterLayout) GetInitialState() map[string]interface{} {
+	return map[string]interface{}{"count": 0}
+}
+
+func (l *counterLayout) Render(ctx *LayoutContext, slot string) string {
+	return fmt.Sprintf(`<button class="%s" data-onclick="increment">%d</button>%s`, l.name, ctx.GetInt("count"), slot)
+}
+
+func (l *counterLayout) HandleEvent(ctx *LayoutContext, eventName string, event Event) {
+	if eventName == "increment" {
+		ctx.Set("count", ctx.GetInt("count")+1)
+	}
+}
+
+// slotPage is the page wrapped by the layouts of the tests
+type slotPage struct {
+	BasePage
+}
+
+func (p *slotPage) Render() string {
+	return `<main>page</main>`
+}
+
+func clickLayout(t *testing.T, h *Headless, selector string) {
+	t.Helper()
+	found, err := h.Query(selector)
+	if err != nil || len(found) == 0 {
+		t.Fatalf("no element matches %q:\n%s", selector, h.HTML())
+	}
+	h.Dispatch(found[0], EventInit{Type: "click"})
+}
+
+func layoutText(t *testing.T, h *Headless, selector string) string {
+	t.Helper()
+	found, err := h.Query(selector)
+	if err != nil || len(found) == 0 {
+		t.Fatalf("no element matches %q:\n%s", selector, h.HTML())
+	}
+	return found[0].Text()
+}
+
+func TestLayoutsMatchingTheSamePrefix(t *testing.T) {
+	previous := globalRouter
+	globalRouter = NewRouter()
+	defer func() { globalRouter = previous }()
+
+	// Both layouts match /, with the same prefix
+	RegisterLayout("/", func() Layout { return &counterLayout{name: "outer"} })
+	RegisterLayout("/:lang?", func() Layout { return &counterLayout{name: "inner"} })
+	RegisterRoute("/:lang?", func() PageInterface { return &slotPage{} })
+
+	h := NewHeadless()
+	defer h.Close()
+	h.Open("/")
+
+	clickLayout(t, h, "button.outer")
+	clickLayout(t, h, "button.inner")
+	clickLayout(t, h, "button.inner")
+
+	if got := layoutText(t, h, "button.outer"); got != "1" {
+		t.Errorf("outer count = %s, want 1", got)
+	}
+	if got := layoutText(t, h, "button.inner"); got != "2" {
+		t.Errorf("inner count = %s, want 2", got)
+	}
+
+	// A new lang remounts the inner layout only
+	h.Navigate("/en")
+	if got := layoutText(t, h, "button.outer"); got != "1" {
+		t.Errorf("outer count = %s after navigating, want 1", got)
+	}
+	if got := layoutText(t, h, "button.inner"); got != "0" {
+		t.Errorf("inner count = %s after navigating, want 0", got)
+	}
+}
@@ -0,0 +1,68 @@
+package framework
+
+// Mounter is implemented by pages and layouts that need to run code once they are in the DOM,
+// for example to start a fetch or focus an element.
+type Mounter interface {
+	OnMount()
+}
+
+// Unmounter is implemented by pages and layouts that need to clean up (timers,
+// goroutines, subscriptions) before the router replaces them.
+type Unmounter interface {
+	OnUnmount()
+}
+
+// Updater is implemented by pages that need to run code after every re-render
+// that follows the first one.
+type Updater interface {
+	OnUpdate()
+}
+
+// LeaveGuard is implemented by pages that may prevent the user from navigating away,
+// for example while a form has unsaved changes. BeforeLeave receives the
+// destination path (empty when the browser tab is being closed or reloaded)
+// and returns false to cancel the navigation.
+type LeaveGuard interface {
+	BeforeLeave(to string) bool
+}
+
+// unmountPage runs the OnUnmount hook of the current page
+func (a *app) unmountPage() {
+	if unmounter, ok := a.page.(Unmounter); ok && a.mounted {
+		// The next page is mounted even if the cleanup panics
+		protect(ErrorInfo{Phase: "unmount"}, unmounter.OnUnmount)
+	}
+	a.mounted = false
+}
+
+// afterRender runs OnMount after the first render of a page and OnUpdate after the next ones.
+// A failed page runs neither, and a hook that panics replaces the page with the error view.
+func (a *app) afterRender() {
+	a.afterLayoutsRender()
+	if a.failure != nil {
+		return
+	}
+	if !a.mounted {
+		a.mounted = true
+		if mounter, ok := a.page.(Mounter); ok {
+			a.failure = protect(ErrorInfo{Phase: "mount"}, mounter.OnMount)
+		}
+	} else if updater, ok := a.page.(Updater); ok {
+		a.failure = protect(ErrorInfo{Phase: "update"}, updater.OnUpdate)
+	}
+	if a.failure != nil {
+		a.render(a.renderLayouts(renderErrorView(*a.failure)))
+	}
+}
+
+// canLeave asks the current page whether navigating to path is allowed
+func (a *app) canLeave(to string) bool {
+	allowed := true
+	if guard, ok := a.page.(LeaveGuard); ok && a.failure == nil {
+		// A guard that panics lets the user leave rather than trapping them on a broken page
+		protect(ErrorInfo{Phase: "leave"}, func() {
+			allowed = guard.BeforeLeave(to)
+		})
+	}
+	return allowed
+}
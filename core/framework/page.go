@@ -0,0 +1,28 @@
+package framework
+
+import StencilText "github.com/RafaelCoppe/Stencil-Go/pkg/text"
+
+// PageInterface represents the interface that user pages must implement
+type PageInterface interface {
+	Render() string
+	HandleEvent(eventName string, event Event)
+	GetInitialState() map[string]interface{}
+}
+
+// BasePage provides a base implementation that users can embed in their pages
+type BasePage struct{}
+
+// GetInitialState provides default empty initial state
+func (p *BasePage) GetInitialState() map[string]interface{} {
+	return make(map[string]interface{})
+}
+
+// HandleEvent provides default empty event handling
+func (p *BasePage) HandleEvent(eventName string, event Event) {
+	// Override this method in your page to handle events
+}
+
+// Render provides a default render method
+func (p *BasePage) Render() string {
+	return StencilText.Titre1("Override the Render method in your page")
+}
@@ -0,0 +1,205 @@
+//go:build js && wasm
+
+package framework
+
+import (
+	"strings"
+	"syscall/js"
+)
+
+const (
+	domElementNode = 1
+	domTextNode    = 3
+	domCommentNode = 8
+
+	svgNamespace = "http://www.w3.org/2000/svg"
+
+	// ignoreAttr marks an element whose content is owned by third-party code
+	ignoreAttr = "data-stencil-ignore"
+)
+
+// patchChildren morphs the children of a live DOM element so that they match nodes.
+// Nodes that are already present are updated in place, which preserves focus,
+// caret position, running CSS transitions and foreign DOM.
+func patchChildren(parent js.Value, nodes []*vnode, namespace string) {
+	document := js.Global().Get("document")
+	childNodes := parent.Get("childNodes")
+
+	// Index keyed live children so they can be moved instead of recreated
+	keyed := make(map[string]js.Value)
+	for i := 0; i < childNodes.Length(); i++ {
+		child := childNodes.Index(i)
+		if key := liveKey(child); key != "" {
+			keyed[key] = child
+		}
+	}
+
+	for i, node := range nodes {
+		var live js.Value
+		if i < childNodes.Length() {
+			live = childNodes.Index(i)
+		}
+
+		if key := node.key(); key != "" {
+			if match, ok := keyed[key]; ok && sameNode(match, node) {
+				delete(keyed, key)
+				if !live.Equal(match) {
+					insertBefore(parent, match, live)
+				}
+				patchNode(match, node, namespace)
+				continue
+			}
+		} else if live.Truthy() && liveKey(live) == "" && sameNode(live, node) {
+			patchNode(live, node, namespace)
+			continue
+		}
+
+		insertBefore(parent, createNode(document, node, namespace), live)
+	}
+
+	// Remove the live children that no longer have a counterpart
+	for childNodes.Length() > len(nodes) {
+		parent.Call("removeChild", parent.Get("lastChild"))
+	}
+}
+
+// insertBefore inserts node before ref, or appends it when ref is undefined
+func insertBefore(parent, node, ref js.Value) {
+	if ref.Truthy() {
+		parent.Call("insertBefore", node, ref)
+	} else {
+		parent.Call("appendChild", node)
+	}
+}
+
+// liveKey returns the reconciliation key of a live DOM node
+func liveKey(live js.Value) string {
+	if live.Get("nodeType").Int() != domElementNode {
+		return ""
+	}
+	key := live.Call("getAttribute", "data-key")
+	if key.IsNull() {
+		return ""
+	}
+	return key.String()
+}
+
+// sameNode reports whether a live DOM node can be patched into node
+func sameNode(live js.Value, node *vnode) bool {
+	nodeType := live.Get("nodeType").Int()
+	switch node.kind {
+	case textNode:
+		return nodeType == domTextNode
+	case commentNode:
+		return nodeType == domCommentNode
+	default:
+		return nodeType == domElementNode &&
+			strings.ToLower(live.Get("nodeName").String()) == node.tag &&
+			liveKey(live) == node.key()
+	}
+}
+
+// patchNode updates a live DOM node in place to match node
+func patchNode(live js.Value, node *vnode, namespace string) {
+	if node.kind != elementNode {
+		if live.Get("nodeValue").String() != node.text {
+			live.Set("nodeValue", node.text)
+		}
+		return
+	}
+
+	if _, ignored := node.getAttr(ignoreAttr); ignored {
+		return
+	}
+
+	patchAttributes(live, node)
+
+	if node.tag == "svg" {
+		namespace = svgNamespace
+	} else if node.tag == "foreignobject" {
+		namespace = ""
+	}
+
+	if node.tag == "textarea" {
+		text := ""
+		if len(node.children) > 0 {
+			text = node.children[0].text
+		}
+		if live.Get("defaultValue").String() != text {
+			live.Set("defaultValue", text)
+			live.Set("value", text)
+		}
+		return
+	}
+
+	patchChildren(live, node.children, namespace)
+}
+
+// patchAttributes synchronises the attributes of a live element with node
+func patchAttributes(live js.Value, node *vnode) {
+	attributes := live.Get("attributes")
+	for i := attributes.Length() - 1; i >= 0; i-- {
+		name := attributes.Index(i).Get("name").String()
+		if _, ok := node.getAttr(name); !ok {
+			live.Call("removeAttribute", name)
+			syncProperty(live, name, "", false)
+		}
+	}
+
+	for _, attr := range node.attrs {
+		current := live.Call("getAttribute", attr.name)
+		if !current.IsNull() && current.String() == attr.value {
+			continue
+		}
+		live.Call("setAttribute", attr.name, attr.value)
+		syncProperty(live, attr.name, attr.value, true)
+	}
+}
+
+// syncProperty mirrors a changed attribute onto the matching DOM property.
+// Properties are only touched when the rendered attribute changes, so values
+// typed by the user into uncontrolled inputs are left alone.
+func syncProperty(live js.Value, name, value string, present bool) {
+	switch name {
+	case "value":
+		if live.Get("value").String() != value {
+			live.Set("value", value)
+		}
+	case "checked", "selected":
+		live.Set(name, present)
+	}
+}
+
+// createNode builds a new live DOM node from node
+func createNode(document js.Value, node *vnode, namespace string) js.Value {
+	switch node.kind {
+	case textNode:
+		return document.Call("createTextNode", node.text)
+	case commentNode:
+		return document.Call("createComment", node.text)
+	}
+
+	if node.tag == "svg" {
+		namespace = svgNamespace
+	}
+
+	var el js.Value
+	if namespace != "" {
+		el = document.Call("createElementNS", namespace, node.tag)
+	} else {
+		el = document.Call("createElement", node.tag)
+	}
+
+	for _, attr := range node.attrs {
+		el.Call("setAttribute", attr.name, attr.value)
+	}
+
+	if node.tag == "foreignobject" {
+		namespace = ""
+	}
+	for _, child := range node.children {
+		el.Call("appendChild", createNode(document, child, namespace))
+	}
+
+	return el
+}
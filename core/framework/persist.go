@@ -0,0 +1,231 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Storage selects the browser storage a persisted key is saved to
+type Storage int
+
+const (
+	// LocalStorage keeps the value until it is cleared, across tabs and restarts
+	LocalStorage Storage = iota
+	// SessionStorage keeps the value for the lifetime of the browser tab
+	SessionStorage
+)
+
+// Migration upgrades the JSON of a persisted value by one schema version
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+// PersistOptions configures how a key is persisted
+type PersistOptions struct {
+	// Storage is LocalStorage by default
+	Storage Storage
+	// Name is the storage key, "stencil:<key>" by default ("stencil:page:<key>" for page keys)
+	Name string
+	// Version is the schema version of the value, bump it when T changes shape
+	Version int
+	// Migrations[n] upgrades a value stored with version n to version n+1
+	Migrations map[int]Migration
+}
+
+// persistedValue is the envelope stored for a persisted key
+type persistedValue struct {
+	Version int             `json:"version"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// persistence is a key registered with Persist
+type persistence struct {
+	entry   entry
+	options PersistOptions
+	decode  func(data json.RawMessage) (interface{}, error)
+}
+
+// persisted holds the keys registered with Persist
+var persisted struct {
+	sync.Mutex
+	keys map[entry]*persistence
+}
+
+// Persist saves a state key to the browser storage and restores it on reload.
+// Every committed SetState of the key is saved as JSON, so pages keep using
+// SetState and GetState unchanged. App keys are restored when the app starts,
+// page keys every time a page is mounted, in place of GetInitialState. Setting
+// the key to nil removes it from the storage.
+//
+//	var theme = framework.NewAppKey[string]("theme")
+//
+//	framework.Persist(theme)
+//	framework.Persist(framework.NewKey[any]("draft"), framework.PersistOptions{Storage: framework.SessionStorage})
+//
+// A stored value with an older Version goes through Migrations. Entries that
+// cannot be migrated or decoded are discarded, the key then keeps its initial
+// value.
+func Persist[T any](k Key[T], options ...PersistOptions) {
+	p := &persistence{
+		entry: k.stateEntry(),
+		decode: func(data json.RawMessage) (interface{}, error) {
+			var value T
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			// Numbers stored in untyped keys keep their precision, see convertNumber
+			decoder.UseNumber()
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	}
+	if len(options) > 0 {
+		p.options = options[0]
+	}
+	if p.options.Name == "" {
+		p.options.Name = "stencil:" + k.name
+		if k.scope == pageScope {
+			p.options.Name = "stencil:page:" + k.name
+		}
+	}
+
+	persisted.Lock()
+	if persisted.keys == nil {
+		persisted.keys = make(map[entry]*persistence)
+	}
+	persisted.keys[p.entry] = p
+	persisted.Unlock()
+
+	// Keys registered once the app runs are restored right away
+	if appInstance != nil && k.scope == appScope {
+		if value, ok := p.load(); ok {
+			appInstance.state.seed(appScope, "", map[string]interface{}{k.name: value})
+		}
+	}
+}
+
+// loadPersisted returns the stored values of the persisted keys of a scope
+func loadPersisted(scope stateScope) map[string]interface{} {
+	persisted.Lock()
+	var keys []*persistence
+	for _, p := range persisted.keys {
+		if p.entry.scope == scope {
+			keys = append(keys, p)
+		}
+	}
+	persisted.Unlock()
+
+	values := make(map[string]interface{})
+	for _, p := range keys {
+		if value, ok := p.load(); ok {
+			values[p.entry.key] = value
+		}
+	}
+	return values
+}
+
+// savePersisted writes the committed values of the persisted keys to the storage
+func savePersisted(committed []mutation) {
+	if len(committed) == 0 {
+		return
+	}
+
+	// Only the last write to a key matters
+	latest := make(map[entry]interface{})
+	var order []entry
+	for _, m := range committed {
+		e := entry{m.scope, m.key}
+		if _, exists := latest[e]; !exists {
+			order = append(order, e)
+		}
+		latest[e] = m.value
+	}
+
+	persisted.Lock()
+	keys := make(map[entry]*persistence, len(order))
+	for _, e := range order {
+		if p, exists := persisted.keys[e]; exists {
+			keys[e] = p
+		}
+	}
+	persisted.Unlock()
+
+	for _, e := range order {
+		if p, exists := keys[e]; exists {
+			p.save(latest[e])
+		}
+	}
+}
+
+// load reads, migrates and decodes the stored value.
+// Corrupted entries are removed so that they are only reported once.
+func (p *persistence) load() (interface{}, bool) {
+	area := storageArea(p.options.Storage)
+	raw, exists := area.getItem(p.options.Name)
+	if !exists {
+		return nil, false
+	}
+
+	value, err := p.decodeStored(raw)
+	if err != nil {
+		warn("stencil: discarding persisted state %q: %v", p.options.Name, err)
+		area.removeItem(p.options.Name)
+		return nil, false
+	}
+	return value, true
+}
+
+// decodeStored decodes an envelope, running the migrations it needs
+func (p *persistence) decodeStored(raw string) (interface{}, error) {
+	var stored persistedValue
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		return nil, err
+	}
+	if stored.Value == nil {
+		return nil, fmt.Errorf("missing value")
+	}
+	if stored.Version > p.options.Version {
+		return nil, fmt.Errorf("stored version %d is newer than %d", stored.Version, p.options.Version)
+	}
+
+	data := stored.Value
+	for version := stored.Version; version < p.options.Version; version++ {
+		migrate, exists := p.options.Migrations[version]
+		if !exists {
+			return nil, fmt.Errorf("no migration from version %d", version)
+		}
+		migrated, err := migrate(data)
+		if err != nil {
+			return nil, fmt.Errorf("migration from version %d: %w", version, err)
+		}
+		data = migrated
+	}
+	return p.decode(data)
+}
+
+// save writes a value to the storage, nil removes it
+func (p *persistence) save(value interface{}) {
+	area := storageArea(p.options.Storage)
+	if value == nil {
+		area.removeItem(p.options.Name)
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err == nil {
+		data, err = json.Marshal(persistedValue{Version: p.options.Version, Value: data})
+	}
+	if err == nil {
+		err = area.setItem(p.options.Name, string(data))
+	}
+	if err != nil {
+		warn("stencil: cannot persist state %q: %v", p.options.Name, err)
+	}
+}
+
+// storage is a key/value string storage such as window.localStorage
+type storage interface {
+	getItem(name string) (string, bool)
+	setItem(name, value string) error
+	removeItem(name string)
+}
@@ -0,0 +1,65 @@
+//go:build js && wasm
+
+package framework
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// webStorage is a storage backed by window.localStorage or window.sessionStorage
+type webStorage struct {
+	name string
+}
+
+// storageArea returns the browser storage selected by s
+func storageArea(s Storage) storage {
+	if s == SessionStorage {
+		return webStorage{name: "sessionStorage"}
+	}
+	return webStorage{name: "localStorage"}
+}
+
+// area returns the storage object, or undefined when the browser denies access to it
+func (w webStorage) area() (area js.Value) {
+	defer func() {
+		// Reading window.localStorage throws when storage is disabled
+		if recover() != nil {
+			area = js.Undefined()
+		}
+	}()
+	return js.Global().Get(w.name)
+}
+
+func (w webStorage) getItem(name string) (string, bool) {
+	area := w.area()
+	if !area.Truthy() {
+		return "", false
+	}
+	item := area.Call("getItem", name)
+	if item.Type() != js.TypeString {
+		return "", false
+	}
+	return item.String(), true
+}
+
+func (w webStorage) setItem(name, value string) (err error) {
+	area := w.area()
+	if !area.Truthy() {
+		return fmt.Errorf("%s is not available", w.name)
+	}
+	defer func() {
+		// setItem throws when the storage quota is exceeded
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	area.Call("setItem", name, value)
+	return nil
+}
+
+func (w webStorage) removeItem(name string) {
+	if area := w.area(); area.Truthy() {
+		area.Call("removeItem", name)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !(js && wasm)
+
+package framework
+
+import "sync"
+
+// memoryStorage is an in-memory storage standing in for the browser storages natively
+type memoryStorage struct {
+	mu    sync.Mutex
+	items map[string]string
+}
+
+var (
+	nativeLocalStorage   = &memoryStorage{items: make(map[string]string)}
+	nativeSessionStorage = &memoryStorage{items: make(map[string]string)}
+)
+
+// storageArea returns the storage selected by s
+func storageArea(s Storage) storage {
+	if s == SessionStorage {
+		return nativeSessionStorage
+	}
+	return nativeLocalStorage
+}
+
+func (m *memoryStorage) getItem(name string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, exists := m.items[name]
+	return value, exists
+}
+
+func (m *memoryStorage) setItem(name, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[name] = value
+	return nil
+}
+
+func (m *memoryStorage) removeItem(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, name)
+}
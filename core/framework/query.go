@@ -0,0 +1,135 @@
+package framework
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NavigateOptions changes the query string of a navigation.
+// Query replaces the query, then MergeQuery sets parameters on top of it and
+// RemoveQuery deletes parameters. Without Query, the options apply to the
+// query of the path, or to the current query when the path is empty or the
+// current path, so a page can update its filters in place:
+//
+//	framework.NavigateTo("", framework.NavigateOptions{MergeQuery: url.Values{"page": {"2"}}})
+//	framework.NavigateTo("", framework.NavigateOptions{RemoveQuery: []string{"q"}})
+type NavigateOptions struct {
+	// Query replaces the query string
+	Query url.Values
+	// MergeQuery sets these parameters, replacing their previous values
+	MergeQuery url.Values
+	// RemoveQuery deletes these parameters
+	RemoveQuery []string
+}
+
+// splitLocation splits a location such as /products?page=2 into its path and raw query
+func splitLocation(location string) (path, rawQuery string) {
+	path, rawQuery, _ = strings.Cut(location, "?")
+	return path, rawQuery
+}
+
+// joinLocation builds a location from a path and a raw query
+func joinLocation(path, rawQuery string) string {
+	if rawQuery == "" {
+		return path
+	}
+	return path + "?" + rawQuery
+}
+
+// parseQuery decodes a raw query, ignoring malformed pairs
+func parseQuery(rawQuery string) url.Values {
+	query, _ := url.ParseQuery(rawQuery)
+	return query
+}
+
+// resolveLocation applies options to the target of a navigation and returns its path and raw query
+func (r *Router) resolveLocation(location string, options NavigateOptions) (path, rawQuery string) {
+	path, rawQuery = splitLocation(location)
+	current := r.GetCurrentPath()
+	if path == "" {
+		path = current
+		if !strings.Contains(location, "?") {
+			rawQuery = r.currentQuery
+		}
+	} else if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	if options.Query == nil && options.MergeQuery == nil && options.RemoveQuery == nil {
+		return path, rawQuery
+	}
+
+	query := parseQuery(rawQuery)
+	if options.Query != nil {
+		query = url.Values{}
+		for key, values := range options.Query {
+			query[key] = append([]string(nil), values...)
+		}
+	} else if !strings.Contains(location, "?") && samePath(path, current) {
+		// Merging into the current path updates its current query
+		query = parseQuery(r.currentQuery)
+	}
+	for key, values := range options.MergeQuery {
+		query[key] = append([]string(nil), values...)
+	}
+	for _, key := range options.RemoveQuery {
+		query.Del(key)
+	}
+	return path, query.Encode()
+}
+
+// Query returns the query parameters of the current location, for example
+// {"page": {"2"}} for /products?page=2. Changing the query with NavigateTo
+// re-renders the page without resetting its state.
+func Query() url.Values {
+	query := url.Values{}
+	if appInstance != nil {
+		for key, values := range appInstance.query {
+			query[key] = append([]string(nil), values...)
+		}
+	}
+	return query
+}
+
+// QueryString returns the first value of a query parameter, or "" when it is absent
+func QueryString(key string) string {
+	if appInstance == nil {
+		return ""
+	}
+	return appInstance.query.Get(key)
+}
+
+// QueryStrings returns every value of a query parameter, such as ?tag=a&tag=b
+func QueryStrings(key string) []string {
+	if appInstance == nil {
+		return nil
+	}
+	return append([]string(nil), appInstance.query[key]...)
+}
+
+// QueryInt returns a query parameter as int, or 0 when it is absent or not an integer
+func QueryInt(key string) int {
+	value, err := strconv.Atoi(QueryString(key))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// QueryBool returns a query parameter as bool: "1", "true" and a parameter
+// without value (?archived) are true
+func QueryBool(key string) bool {
+	if appInstance == nil {
+		return false
+	}
+	values, exists := appInstance.query[key]
+	if !exists {
+		return false
+	}
+	if values[0] == "" {
+		return true
+	}
+	value, _ := strconv.ParseBool(values[0])
+	return value
+}
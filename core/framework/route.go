@@ -0,0 +1,251 @@
+package framework
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// segmentKind is the kind of a segment of a route path
+type segmentKind int
+
+const (
+	staticSegment           segmentKind = iota // users
+	paramSegment                               // :name
+	optionalSegment                            // :name?
+	catchAllSegment                            // *name, one or more segments
+	optionalCatchAllSegment                    // *name?, zero or more segments
+)
+
+// catchAllParam is the parameter name of an anonymous catch-all, as in /admin/*
+const catchAllParam = "*"
+
+// routeSegment is a segment of a route path, value is the text of a static
+// segment or the name of a parameter
+type routeSegment struct {
+	kind  segmentKind
+	value string
+}
+
+// routePattern is a route path with parameters, such as /users/:id or /docs/*slug
+type routePattern struct {
+	path     string
+	segments []routeSegment
+	handler  RouteHandler
+}
+
+// isRoutePattern reports whether path has parameter or catch-all segments
+func isRoutePattern(path string) bool {
+	return strings.Contains(path, "/:") || strings.Contains(path, "/*")
+}
+
+// parseRoutePattern splits a route path into segments
+func parseRoutePattern(path string, handler RouteHandler) routePattern {
+	p := routePattern{path: path, handler: handler}
+	for _, part := range splitPath(path) {
+		switch {
+		case strings.HasPrefix(part, "*"):
+			segment := routeSegment{kind: catchAllSegment, value: strings.TrimSuffix(part[1:], "?")}
+			if strings.HasSuffix(part, "?") {
+				segment.kind = optionalCatchAllSegment
+			}
+			if segment.value == "" {
+				segment.value = catchAllParam
+			}
+			p.segments = append(p.segments, segment)
+		case strings.HasPrefix(part, ":") && strings.HasSuffix(part, "?"):
+			p.segments = append(p.segments, routeSegment{kind: optionalSegment, value: part[1 : len(part)-1]})
+		case strings.HasPrefix(part, ":"):
+			p.segments = append(p.segments, routeSegment{kind: paramSegment, value: part[1:]})
+		default:
+			p.segments = append(p.segments, routeSegment{kind: staticSegment, value: part})
+		}
+	}
+	return p
+}
+
+// splitPath returns the segments of a path, ignoring the leading and trailing slashes
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// match returns the parameters of path if it matches the pattern
+func (p routePattern) match(path string) (map[string]string, bool) {
+	params := make(map[string]string)
+	if !matchSegments(p.segments, splitPath(path), params) {
+		return nil, false
+	}
+	return params, true
+}
+
+// matchPrefix returns the beginning of path matched by the pattern, the
+// longest one when there are several: /users/42 for /users/:id on /users/42/edit
+func (p routePattern) matchPrefix(path string) (string, bool) {
+	parts := splitPath(path)
+	for n := len(parts); n >= 0; n-- {
+		if matchSegments(p.segments, parts[:n], make(map[string]string)) {
+			return "/" + strings.Join(parts[:n], "/"), true
+		}
+	}
+	return "", false
+}
+
+// matchSegments matches path segments against pattern segments. An optional
+// parameter is tried with a value first, then without one, and a catch-all
+// takes as many segments as the rest of the pattern lets it.
+func matchSegments(segments []routeSegment, parts []string, params map[string]string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	segment := segments[0]
+	switch segment.kind {
+	case catchAllSegment, optionalCatchAllSegment:
+		least := 1
+		if segment.kind == optionalCatchAllSegment {
+			least = 0
+		}
+		for n := len(parts); n >= least; n-- {
+			values := make([]string, n)
+			for i, part := range parts[:n] {
+				value, err := url.PathUnescape(part)
+				if err != nil {
+					return false
+				}
+				values[i] = value
+			}
+			if matchSegments(segments[1:], parts[n:], params) {
+				params[segment.value] = strings.Join(values, "/")
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(parts) > 0 {
+		if segment.kind == staticSegment {
+			if parts[0] == segment.value && matchSegments(segments[1:], parts[1:], params) {
+				return true
+			}
+		} else if value, err := url.PathUnescape(parts[0]); err == nil && matchSegments(segments[1:], parts[1:], params) {
+			params[segment.value] = value
+			return true
+		}
+	}
+	return segment.kind == optionalSegment && matchSegments(segments[1:], parts, params)
+}
+
+// compareRoutes orders patterns from the most specific: segment by segment,
+// static segments come before parameters, parameters before optional ones
+// and catch-alls come last. A pattern that ends is more specific than one
+// going on with an optional segment, which matches the same paths and more.
+// Patterns of the same specificity are ordered by path, so the order never
+// depends on the registration order.
+func compareRoutes(a, b routePattern) int {
+	for i := 0; i < len(a.segments) || i < len(b.segments); i++ {
+		if rankA, rankB := segmentRank(a.segments, i), segmentRank(b.segments, i); rankA != rankB {
+			return rankB - rankA
+		}
+	}
+	return strings.Compare(a.path, b.path)
+}
+
+// segmentRank ranks the segment i of a pattern for compareRoutes
+func segmentRank(segments []routeSegment, i int) int {
+	if i >= len(segments) {
+		return 4 // between a parameter and an optional parameter
+	}
+	switch segments[i].kind {
+	case staticSegment:
+		return 6
+	case paramSegment:
+		return 5
+	case optionalSegment:
+		return 3
+	case catchAllSegment:
+		return 2
+	}
+	return 1
+}
+
+// addPattern registers a pattern, replacing one with the same segments
+func (r *Router) addPattern(p routePattern) {
+	for i, existing := range r.patterns {
+		if strings.Trim(existing.path, "/") == strings.Trim(p.path, "/") {
+			r.patterns[i] = p
+			return
+		}
+	}
+	r.patterns = append(r.patterns, p)
+	sort.SliceStable(r.patterns, func(i, j int) bool {
+		return compareRoutes(r.patterns[i], r.patterns[j]) < 0
+	})
+}
+
+// BuildPath fills the parameters of a route pattern:
+//
+//	framework.BuildPath("/users/:id/edit", map[string]string{"id": "42"}) // "/users/42/edit"
+//
+// Missing optional parameters are left out, missing required ones are left
+// empty. The value of a catch-all may span several segments: "guides/intro"
+// for /docs/*slug gives /docs/guides/intro.
+func BuildPath(pattern string, params map[string]string) string {
+	var b strings.Builder
+	for _, segment := range parseRoutePattern(pattern, nil).segments {
+		value := segment.value
+		switch segment.kind {
+		case paramSegment, optionalSegment:
+			value = url.PathEscape(params[segment.value])
+		case catchAllSegment, optionalCatchAllSegment:
+			parts := strings.Split(params[segment.value], "/")
+			for i, part := range parts {
+				parts[i] = url.PathEscape(part)
+			}
+			value = strings.Join(parts, "/")
+		}
+		if value == "" && (segment.kind == optionalSegment || segment.kind == optionalCatchAllSegment) {
+			continue
+		}
+		b.WriteString("/")
+		b.WriteString(value)
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// Params returns the parameters of the current route, for example
+// {"id": "42"} for /users/42 on the route /users/:id
+func Params() map[string]string {
+	params := make(map[string]string)
+	if appInstance != nil {
+		for name, value := range appInstance.params {
+			params[name] = value
+		}
+	}
+	return params
+}
+
+// Param returns a parameter of the current route, or "" when the route has no
+// such parameter or an optional parameter is absent. A catch-all gives the
+// segments it matched joined by '/', an anonymous one is named "*".
+func Param(name string) string {
+	if appInstance == nil {
+		return ""
+	}
+	return appInstance.params[name]
+}
+
+// ParamInt returns a parameter of the current route as int, or 0 when it is absent or not an integer
+func ParamInt(name string) int {
+	value, err := strconv.Atoi(Param(name))
+	if err != nil {
+		return 0
+	}
+	return value
+}
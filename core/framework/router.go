@@ -1,10 +1,10 @@
-//go:build js && wasm
-
 package framework
 
 import (
+	"sort"
 	"strings"
-	"syscall/js"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
 )
 
 // RouteHandler represents a function that returns a PageInterface
@@ -12,9 +12,16 @@ type RouteHandler func() PageInterface
 
 // Router manages application routing
 type Router struct {
-	routes      map[string]RouteHandler
-	currentPath string
-	basePath    string
+	routes       map[string]RouteHandler
+	patterns     []routePattern // routes with parameters, most specific first, see route.go
+	currentPath  string
+	currentQuery string // raw query string of the current location, see query.go
+	notFound     RouteHandler
+	layouts      []*layoutRoute // shortest prefix first, see layout.go
+	basePath     string
+
+	host   host.Host // location and history, attached when the application starts
+	detach func()
 }
 
 // NewRouter creates a new router instance
@@ -32,18 +39,67 @@ var globalRouter *Router
 func InitRouter() *Router {
 	if globalRouter == nil {
 		globalRouter = NewRouter()
-		setupBrowserRouting()
 	}
 	return globalRouter
 }
 
-// RegisterRoute registers a route with its handler
+// attach makes the router follow the location and history of h
+func (r *Router) attach(h host.Host) {
+	if r.detach != nil {
+		r.detach()
+	}
+	r.host = h
+
+	// Handle back/forward navigation
+	stopPopState := h.Listen("popstate", func(host.Event) {
+		location := h.Location()
+		path, rawQuery := splitLocation(location)
+		if r.currentPath != "" && samePath(path, r.currentPath) {
+			// Only the query changed, the page stays
+			r.setQuery(rawQuery)
+			return
+		}
+		if appInstance != nil && !appInstance.canLeave(location) {
+			// The browser already changed the URL, put the current page back
+			h.PushHistory(joinLocation(r.currentPath, r.currentQuery))
+			return
+		}
+		r.currentPath = ""
+		r.render()
+	})
+
+	// Let the current page warn before the tab is closed or reloaded
+	stopBeforeUnload := h.Listen("beforeunload", func(event host.Event) {
+		if appInstance != nil && !appInstance.canLeave("") {
+			event.PreventDefault()
+		}
+	})
+
+	r.detach = func() {
+		stopPopState()
+		stopBeforeUnload()
+		r.host = nil
+		r.detach = nil
+	}
+}
+
+// RegisterRoute registers a route with its handler.
+// Segments starting with ':' are parameters, read by the page with Param:
+// /users/:id matches /users/42, and /posts/:page? matches /posts and /posts/2.
+// A segment starting with '*' is a catch-all matching the rest of the path:
+// /docs/*slug matches /docs/guides/intro, /docs/*slug? also matches /docs,
+// and /admin/* is a fallback for the unknown paths of a section.
+// Static routes take precedence over the others, which are tried from the
+// most specific, see compareRoutes.
 func (r *Router) RegisterRoute(path string, handler RouteHandler) {
 	// Normalize path
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
 	r.routes[path] = handler
+	if isRoutePattern(path) {
+		r.addPattern(parseRoutePattern(path, handler))
+	}
 }
 
 // RegisterPageRoute registers routes for page, create, and edit actions
@@ -66,28 +122,60 @@ func (r *Router) RegisterPageRoute(basePath string, pageHandler, createHandler,
 	}
 }
 
-// Navigate to a specific path
-func (r *Router) Navigate(path string) {
-	if !strings.HasPrefix(path, "/") {
-		path = "/" + path
+// Navigate to a specific path.
+// The path may have a query string, options change it, see NavigateOptions.
+// A navigation to the current path with another query re-renders the page
+// without mounting it again, so its state is kept.
+func (r *Router) Navigate(path string, options ...NavigateOptions) {
+	var o NavigateOptions
+	if len(options) > 0 {
+		o = options[0]
+	}
+	path, rawQuery := r.resolveLocation(path, o)
+	location := joinLocation(path, rawQuery)
+
+	if appInstance != nil && appInstance.page != nil && samePath(path, r.currentPath) {
+		if rawQuery != r.currentQuery && r.host != nil {
+			r.host.PushHistory(location)
+		}
+		r.setQuery(rawQuery)
+		return
+	}
+
+	// Give the current page a chance to cancel the navigation
+	if appInstance != nil && !appInstance.canLeave(location) {
+		return
 	}
 
 	r.currentPath = path
+	r.currentQuery = rawQuery
 
 	// Update browser URL without reloading
-	js.Global().Get("history").Call("pushState", js.Null(), "", path)
+	if r.host != nil {
+		r.host.PushHistory(location)
+	}
 
 	// Render the new page
 	r.render()
 }
 
-// GetCurrentPath returns the current path
+// setQuery changes the query of the current page and renders it with its state
+func (r *Router) setQuery(rawQuery string) {
+	r.currentQuery = rawQuery
+	if appInstance != nil {
+		appInstance.query = parseQuery(rawQuery)
+		appInstance.update()
+	}
+}
+
+// GetCurrentPath returns the current path, without the query string
 func (r *Router) GetCurrentPath() string {
 	if r.currentPath == "" {
 		// Get current path from browser
-		location := js.Global().Get("location")
-		pathname := location.Get("pathname").String()
-		r.currentPath = pathname
+		r.currentPath = "/"
+		if r.host != nil {
+			r.currentPath, r.currentQuery = splitLocation(r.host.Location())
+		}
 	}
 	return r.currentPath
 }
@@ -97,91 +185,122 @@ func (r *Router) render() {
 	path := r.GetCurrentPath()
 
 	// Find matching route
-	handler := r.findRoute(path)
+	handler, params := r.findRoute(path)
 	if handler == nil {
-		// Try default route
-		handler = r.routes["/"]
-		if handler == nil {
-			// Render 404 page
-			r.render404()
-			return
-		}
+		// Unknown paths get the 404 page, a section fallback is a catch-all route such as /admin/*
+		r.render404()
+		return
 	}
 
-	// Get page instance and render
-	page := handler()
+	// Get page instance, mount it with fresh page-scoped state and render
+	page := newRoutePage(handler, path)
 	if appInstance != nil {
-		appInstance.setPage(page)
+		appInstance.params = params
+		appInstance.query = parseQuery(r.currentQuery)
+		appInstance.mount(page)
 		appInstance.update()
 	}
 }
 
-// findRoute finds the best matching route for a path
-func (r *Router) findRoute(path string) RouteHandler {
+// newRoutePage calls the route handler of path, a handler that panics gives the error page
+func newRoutePage(handler RouteHandler, path string) PageInterface {
+	var page PageInterface
+	if failure := protect(ErrorInfo{Phase: "route", Path: path}, func() { page = handler() }); failure != nil {
+		return &errorPage{info: *failure}
+	}
+	return page
+}
+
+// findRoute finds the best matching route for a path, with the parameters it captures
+func (r *Router) findRoute(path string) (RouteHandler, map[string]string) {
 	// First try exact match
-	if handler, exists := r.routes[path]; exists {
-		return handler
+	if handler, exists := r.routes[path]; exists && !isRoutePattern(path) {
+		return handler, nil
 	}
 
 	// Remove trailing slash and try again
 	if strings.HasSuffix(path, "/") && len(path) > 1 {
 		trimmedPath := strings.TrimSuffix(path, "/")
-		if handler, exists := r.routes[trimmedPath]; exists {
-			return handler
+		if handler, exists := r.routes[trimmedPath]; exists && !isRoutePattern(trimmedPath) {
+			return handler, nil
 		}
 	}
 
 	// Try with trailing slash
 	if !strings.HasSuffix(path, "/") {
 		pathWithSlash := path + "/"
-		if handler, exists := r.routes[pathWithSlash]; exists {
-			return handler
+		if handler, exists := r.routes[pathWithSlash]; exists && !isRoutePattern(pathWithSlash) {
+			return handler, nil
+		}
+	}
+
+	// Then the routes with parameters, the most specific first
+	for _, pattern := range r.patterns {
+		if params, ok := pattern.match(path); ok {
+			return pattern.handler, params
 		}
 	}
 
-	return nil
+	return nil, nil
+}
+
+// Paths returns the registered route paths, sorted, without the trailing
+// slash variants registered by RegisterPageRoute. Routes with parameters are
+// listed as registered, such as /users/:id.
+func (r *Router) Paths() []string {
+	paths := make([]string, 0, len(r.routes))
+	for path := range r.routes {
+		if path != "/" && strings.HasSuffix(path, "/") {
+			if _, exists := r.routes[strings.TrimSuffix(path, "/")]; exists {
+				continue
+			}
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
 }
 
-// render404 renders a 404 page
+// samePath reports whether two paths name the same route, ignoring a trailing slash
+func samePath(a, b string) bool {
+	if len(a) > 1 {
+		a = strings.TrimSuffix(a, "/")
+	}
+	if len(b) > 1 {
+		b = strings.TrimSuffix(b, "/")
+	}
+	return a == b
+}
+
+// render404 renders the 404 page
 func (r *Router) render404() {
-	notFoundPage := &notFoundPage{}
+	page := r.notFoundPage(r.currentPath)
 	if appInstance != nil {
-		appInstance.setPage(notFoundPage)
+		appInstance.params = nil
+		appInstance.query = parseQuery(r.currentQuery)
+		appInstance.mount(page)
 		appInstance.update()
 	}
 }
 
-// setupBrowserRouting sets up browser navigation event listeners
-func setupBrowserRouting() {
-	// Handle back/forward navigation
-	js.Global().Call("addEventListener", "popstate", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		if globalRouter != nil {
-			globalRouter.currentPath = ""
-			globalRouter.render()
-		}
-		return nil
-	}))
-
-	// Handle initial load - execute immediately if DOM is already loaded
-	if js.Global().Get("document").Get("readyState").String() == "complete" {
-		if globalRouter != nil {
-			globalRouter.render()
-		}
-	} else {
-		// Handle initial load when DOM becomes ready
-		js.Global().Call("addEventListener", "DOMContentLoaded", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			if globalRouter != nil {
-				globalRouter.render()
-			}
-			return nil
-		}))
+// notFoundPage returns the page rendered when no route matches path
+func (r *Router) notFoundPage(path string) PageInterface {
+	if r.notFound == nil {
+		return &notFoundPage{}
 	}
+	return newRoutePage(r.notFound, path)
+}
+
+// RegisterNotFound replaces the page rendered when no route matches the path.
+// Server renders still answer it with a 404 status.
+func (r *Router) RegisterNotFound(handler RouteHandler) {
+	r.notFound = handler
 }
 
-// NavigateTo navigates to a path (global function)
-func NavigateTo(path string) {
+// NavigateTo navigates to a path (global function), see Router.Navigate
+func NavigateTo(path string, options ...NavigateOptions) {
 	if globalRouter != nil {
-		globalRouter.Navigate(path)
+		globalRouter.Navigate(path, options...)
 	}
 }
 
@@ -197,6 +316,11 @@ func RegisterPageRoute(basePath string, pageHandler, createHandler, editHandler
 	router.RegisterPageRoute(basePath, pageHandler, createHandler, editHandler)
 }
 
+// RegisterNotFound replaces the global 404 page, see Router.RegisterNotFound
+func RegisterNotFound(handler RouteHandler) {
+	InitRouter().RegisterNotFound(handler)
+}
+
 // GetRouter returns the global router instance
 func GetRouter() *Router {
 	return InitRouter()
@@ -0,0 +1,69 @@
+package framework
+
+// invalidate marks the app as needing a render.
+// Renders are coalesced: however many times the state changes, the page is
+// rendered at most once per animation frame. It is safe to call from any
+// goroutine, the batch depth is only looked at by the render loop in frame.
+func (a *app) invalidate() {
+	a.dirty.Store(true)
+	a.requestFrame()
+}
+
+// requestFrame schedules a render on the next frame of the host
+func (a *app) requestFrame() {
+	if a.host == nil || !a.frameRequested.CompareAndSwap(false, true) {
+		return
+	}
+	a.host.RequestFrame(a.frame)
+}
+
+// frame renders the changes made since the frame was requested.
+// A frame running inside a batch renders nothing, the batch requests another one when it ends.
+func (a *app) frame() {
+	a.frameRequested.Store(false)
+	if a.batchDepth == 0 {
+		a.flush()
+	}
+}
+
+// flush renders immediately if a render is pending
+func (a *app) flush() {
+	if a.dirty.Load() {
+		a.update()
+	}
+}
+
+// batch runs fn and defers any render it triggers until fn returns
+func (a *app) batch(fn func()) {
+	a.batchDepth++
+	defer func() {
+		a.batchDepth--
+		if a.batchDepth == 0 && a.dirty.Load() {
+			a.requestFrame()
+		}
+	}()
+	fn()
+}
+
+// Batch groups several state changes into a single render.
+// Renders are already coalesced per animation frame, Batch additionally
+// guarantees that no frame renders a half-applied set of changes.
+// Like Flush, it must be called from the render loop, for example in
+// HandleEvent: writes made by goroutines are already queued in call order.
+func Batch(fn func()) {
+	if appInstance == nil {
+		fn()
+		return
+	}
+	appInstance.batch(fn)
+}
+
+// Flush renders pending state changes synchronously.
+// Use it for the rare cases that need the DOM to be up to date right away,
+// for example to measure an element after changing the state. It must be
+// called from the render loop.
+func Flush() {
+	if appInstance != nil && appInstance.batchDepth == 0 {
+		appInstance.flush()
+	}
+}
@@ -0,0 +1,311 @@
+package framework
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selector is a parsed CSS selector list, matched against virtual DOM nodes.
+// It supports type, universal, #id, .class and attribute selectors
+// ([a], [a=v], [a~=v], [a^=v], [a$=v], [a*=v]), :not() of those, and the
+// descendant and child combinators.
+type selector []complexSelector
+
+// complexSelector is a chain of compound selectors, the last one matches the element
+type complexSelector struct {
+	parts       []compoundSelector
+	combinators []byte // combinators[i] joins parts[i] and parts[i+1]: ' ' or '>'
+}
+
+// compoundSelector is a sequence of simple selectors that all match the same element
+type compoundSelector struct {
+	tag     string // "" or "*" for any element
+	id      string
+	classes []string
+	attrs   []attrSelector
+	not     []compoundSelector
+}
+
+// attrSelector matches an attribute, op is empty for a presence test
+type attrSelector struct {
+	name  string
+	op    string
+	value string
+}
+
+// parseSelector parses a selector list such as "form .btn, [data-onclick=save]"
+func parseSelector(s string) (selector, error) {
+	p := &selectorParser{src: s}
+	var list selector
+	for {
+		complex, err := p.complex()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, complex)
+		p.skipSpace()
+		if p.done() {
+			return list, nil
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		p.pos++
+	}
+}
+
+// selectorParser is a small recursive descent parser over a selector string
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) done() bool { return p.pos >= len(p.src) }
+func (p *selectorParser) peek() byte { return p.src[p.pos] }
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.done() && isSpace(p.peek()) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid selector %q at %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *selectorParser) complex() (complexSelector, error) {
+	var c complexSelector
+	p.skipSpace()
+	for {
+		part, err := p.compound()
+		if err != nil {
+			return c, err
+		}
+		c.parts = append(c.parts, part)
+
+		spaced := p.skipSpace()
+		if p.done() || p.peek() == ',' || p.peek() == ')' {
+			return c, nil
+		}
+		if p.peek() == '>' {
+			p.pos++
+			p.skipSpace()
+			c.combinators = append(c.combinators, '>')
+		} else if spaced {
+			c.combinators = append(c.combinators, ' ')
+		} else {
+			return c, p.errorf("unexpected %q", p.peek())
+		}
+	}
+}
+
+func (p *selectorParser) compound() (compoundSelector, error) {
+	var c compoundSelector
+	start := p.pos
+	if !p.done() && p.peek() == '*' {
+		c.tag = "*"
+		p.pos++
+	} else if name := p.ident(); name != "" {
+		c.tag = strings.ToLower(name)
+	}
+
+	for !p.done() {
+		switch p.peek() {
+		case '#':
+			p.pos++
+			if c.id = p.ident(); c.id == "" {
+				return c, p.errorf("expected an id")
+			}
+		case '.':
+			p.pos++
+			class := p.ident()
+			if class == "" {
+				return c, p.errorf("expected a class name")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			attr, err := p.attr()
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, attr)
+		case ':':
+			if !strings.HasPrefix(p.src[p.pos:], ":not(") {
+				return c, p.errorf("unsupported pseudo-class")
+			}
+			p.pos += len(":not(")
+			p.skipSpace()
+			not, err := p.compound()
+			if err != nil {
+				return c, err
+			}
+			p.skipSpace()
+			if p.done() || p.peek() != ')' {
+				return c, p.errorf("expected )")
+			}
+			p.pos++
+			c.not = append(c.not, not)
+		default:
+			if p.pos == start {
+				return c, p.errorf("unexpected %q", p.peek())
+			}
+			return c, nil
+		}
+	}
+	if p.pos == start {
+		return c, p.errorf("empty selector")
+	}
+	return c, nil
+}
+
+func (p *selectorParser) attr() (attrSelector, error) {
+	var a attrSelector
+	p.pos++ // [
+	p.skipSpace()
+	if a.name = strings.ToLower(p.ident()); a.name == "" {
+		return a, p.errorf("expected an attribute name")
+	}
+	p.skipSpace()
+	if p.done() {
+		return a, p.errorf("expected ]")
+	}
+	if p.peek() != ']' {
+		for _, op := range []string{"=", "~=", "^=", "$=", "*="} {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				a.op = op
+				p.pos += len(op)
+				break
+			}
+		}
+		if a.op == "" {
+			return a, p.errorf("unsupported attribute operator")
+		}
+		p.skipSpace()
+		if !p.done() && (p.peek() == '"' || p.peek() == '\'') {
+			quote := p.peek()
+			end := strings.IndexByte(p.src[p.pos+1:], quote)
+			if end < 0 {
+				return a, p.errorf("unterminated string")
+			}
+			a.value = p.src[p.pos+1 : p.pos+1+end]
+			p.pos += end + 2
+		} else {
+			a.value = p.ident()
+		}
+		p.skipSpace()
+	}
+	if p.done() || p.peek() != ']' {
+		return a, p.errorf("expected ]")
+	}
+	p.pos++
+	return a, nil
+}
+
+// ident reads a name made of letters, digits, '-', '_' and ':' escaped as "\:"
+func (p *selectorParser) ident() string {
+	var b strings.Builder
+	for !p.done() {
+		c := p.peek()
+		if c == '\\' && p.pos+1 < len(p.src) {
+			b.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if !isLetter(c) && !(c >= '0' && c <= '9') && c != '-' && c != '_' && c < 0x80 {
+			break
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return b.String()
+}
+
+// match reports whether node, whose ancestors are listed from the closest, matches the selector
+func (s selector) match(node *vnode, ancestors []*vnode) bool {
+	for _, c := range s {
+		if c.match(node, ancestors) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c complexSelector) match(node *vnode, ancestors []*vnode) bool {
+	last := len(c.parts) - 1
+	if !c.parts[last].match(node) {
+		return false
+	}
+	return c.matchAncestors(last-1, ancestors)
+}
+
+// matchAncestors matches parts[:i+1] against the ancestors, backtracking over descendant combinators
+func (c complexSelector) matchAncestors(i int, ancestors []*vnode) bool {
+	if i < 0 {
+		return true
+	}
+	for j, ancestor := range ancestors {
+		if c.parts[i].match(ancestor) && c.matchAncestors(i-1, ancestors[j+1:]) {
+			return true
+		}
+		if c.combinators[i] == '>' {
+			return false
+		}
+	}
+	return false
+}
+
+func (c compoundSelector) match(node *vnode) bool {
+	if node.kind != elementNode {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != node.tag {
+		return false
+	}
+	if c.id != "" {
+		if id, _ := node.getAttr("id"); id != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		class, _ := node.getAttr("class")
+		names := strings.Fields(class)
+		for _, want := range c.classes {
+			if !containsString(names, want) {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		if !a.match(node) {
+			return false
+		}
+	}
+	for _, not := range c.not {
+		if not.match(node) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a attrSelector) match(node *vnode) bool {
+	value, exists := node.getAttr(a.name)
+	if !exists {
+		return false
+	}
+	switch a.op {
+	case "=":
+		return value == a.value
+	case "~=":
+		return containsString(strings.Fields(value), a.value)
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package framework
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// HTTP statuses returned by RenderPath, net/http is kept out of the wasm binary
+const (
+	statusOK            = 200
+	statusNotFound      = 404
+	statusInternalError = 500
+)
+
+// ssrMu serializes server renders, they temporarily replace the global app instance
+var ssrMu sync.Mutex
+
+// RenderPath renders the page registered for path with its initial state,
+// inside its layouts. The path may have a query string, read by the page
+// with Query. It returns the HTML of the page and the HTTP status of the
+// route: 404, with the not found page, when no route matches, and 500, with
+// the error view, when the route handler, the page or a layout panics.
+// Lifecycle hooks do not run, so a page that loads data in OnMount renders
+// its initial state.
+func RenderPath(path string) (html string, status int) {
+	snapshot := Prerender(path)
+	return snapshot.HTML, snapshot.Status
+}
+
+// Prerender renders the page registered for path like RenderPath, and keeps
+// the page state so that the browser can resume from it, see Snapshot.Document
+func Prerender(path string) Snapshot {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	routePath, _ := splitLocation(path)
+
+	var page PageInterface
+	status := statusOK
+	router := InitRouter()
+	handler, params := router.findRoute(routePath)
+	if handler != nil {
+		page = newRoutePage(handler, routePath)
+	} else {
+		page = router.notFoundPage(routePath)
+		status = statusNotFound
+	}
+	return prerenderPage(path, page, params, status)
+}
+
+// prerenderPage renders page with its initial state and the route parameters on a temporary app instance
+func prerenderPage(path string, page PageInterface, params map[string]string, status int) Snapshot {
+	ssrMu.Lock()
+	defer ssrMu.Unlock()
+
+	// Pages and components reach the app through appInstance while they render
+	previous := appInstance
+	appInstance = newAppCore()
+	defer func() { appInstance = previous }()
+	routePath, rawQuery := splitLocation(path)
+	appInstance.prerenderPath = routePath
+	appInstance.params = params
+	appInstance.query = parseQuery(rawQuery)
+
+	appInstance.mount(page)
+	html := appInstance.renderToString()
+	snapshot := Snapshot{
+		Path:   path,
+		HTML:   html,
+		Status: status,
+		State:  appInstance.state.snapshot(pageScope),
+	}
+	if failure := appInstance.failure; failure != nil {
+		snapshot.Status = statusInternalError
+		snapshot.Err = failure.Err
+	} else if errorPage, ok := page.(*errorPage); ok {
+		snapshot.Status = statusInternalError
+		snapshot.Err = errorPage.info.Err
+	}
+	for _, layout := range appInstance.layouts {
+		if layout.failure != nil && snapshot.Err == nil {
+			snapshot.Status = statusInternalError
+			snapshot.Err = layout.failure.Err
+		}
+	}
+	return snapshot
+}
+
+// renderToString renders the mounted page once, without touching the DOM or running lifecycle hooks
+func (a *app) renderToString() string {
+	a.commit()
+	if a.page == nil {
+		return ""
+	}
+	return a.renderPage()
+}
+
+// injectContainer replaces the content of the element with the given id in an HTML document
+func injectContainer(document, id, content string) (string, error) {
+	at := strings.Index(document, `id="`+id+`"`)
+	if at < 0 {
+		return "", fmt.Errorf("no element with id %q", id)
+	}
+	start := strings.LastIndexByte(document[:at], '<')
+	end := strings.IndexByte(document[at:], '>')
+	if start < 0 || end < 0 {
+		return "", fmt.Errorf("malformed element with id %q", id)
+	}
+	contentStart := at + end + 1
+
+	nameEnd := start + 1
+	for nameEnd < len(document) && isTagNameChar(document[nameEnd]) {
+		nameEnd++
+	}
+	tag := strings.ToLower(document[start+1 : nameEnd])
+	if tag == "" {
+		return "", fmt.Errorf("malformed element with id %q", id)
+	}
+
+	// Find the matching end tag, skipping nested elements of the same name
+	lower := strings.ToLower(document)
+	depth := 1
+	for i := contentStart; i < len(lower); {
+		next := strings.IndexByte(lower[i:], '<')
+		if next < 0 {
+			break
+		}
+		i += next
+		switch {
+		case strings.HasPrefix(lower[i:], "</"+tag) && isTagBoundary(lower, i+2+len(tag)):
+			depth--
+			if depth == 0 {
+				return document[:contentStart] + content + document[i:], nil
+			}
+		case strings.HasPrefix(lower[i:], "<"+tag) && isTagBoundary(lower, i+1+len(tag)):
+			depth++
+		}
+		i++
+	}
+	return "", fmt.Errorf("element with id %q is not closed", id)
+}
+
+// isTagBoundary reports whether a tag name ends at position i of s
+func isTagBoundary(s string, i int) bool {
+	return i >= len(s) || !isTagNameChar(s[i])
+}
@@ -0,0 +1,96 @@
+//go:build !(js && wasm)
+
+package framework
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// SSROptions configures NewSSRHandler
+type SSROptions struct {
+	// Index is the HTML shell the pages are rendered into, "core/index.html" by default
+	Index string
+	// StaticDir holds app.wasm, wasm_exec.js and the other static files, "core" by default
+	StaticDir string
+	// ContainerID is the id of the element the pages are rendered into, "app" by default
+	ContainerID string
+}
+
+// ssrHandler serves pre-rendered pages and static files
+type ssrHandler struct {
+	options SSROptions
+	shell   string
+	files   http.Handler
+}
+
+// NewSSRHandler returns an http.Handler that renders the page registered for
+// the request path into the container of the index.html shell. Crawlers and
+// visitors without JavaScript get the content of the page, and the wasm app
+// resumes from the serialized state and takes over the same markup once it
+// has loaded. Requests for files (paths with an extension, such as /app.wasm)
+// are served from StaticDir.
+//
+// Routes must be registered before the handler serves requests:
+//
+//	app.RegisterRoutes()
+//	handler, err := framework.NewSSRHandler()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(http.ListenAndServe(":8080", handler))
+func NewSSRHandler(options ...SSROptions) (http.Handler, error) {
+	h := &ssrHandler{}
+	if len(options) > 0 {
+		h.options = options[0]
+	}
+	if h.options.Index == "" {
+		h.options.Index = "core/index.html"
+	}
+	if h.options.StaticDir == "" {
+		h.options.StaticDir = "core"
+	}
+	if h.options.ContainerID == "" {
+		h.options.ContainerID = "app"
+	}
+
+	shell, err := os.ReadFile(h.options.Index)
+	if err != nil {
+		return nil, err
+	}
+	// Fail early rather than on the first request if the shell has no container
+	if _, err := injectContainer(string(shell), h.options.ContainerID, ""); err != nil {
+		return nil, err
+	}
+	h.shell = string(shell)
+	h.files = http.FileServer(http.Dir(h.options.StaticDir))
+	return h, nil
+}
+
+func (h *ssrHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if path.Ext(r.URL.Path) != "" {
+		if info, err := os.Stat(filepath.Join(h.options.StaticDir, filepath.FromSlash(path.Clean(r.URL.Path)))); err == nil && !info.IsDir() {
+			h.files.ServeHTTP(w, r)
+			return
+		}
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshot := Prerender(joinLocation(r.URL.Path, r.URL.RawQuery))
+	document, err := snapshot.Document(h.shell, h.options.ContainerID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(snapshot.Status)
+	if r.Method != http.MethodHead {
+		w.Write([]byte(document))
+	}
+}
@@ -0,0 +1,251 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ErrStateNotFound is returned when a state key has never been set
+var ErrStateNotFound = errors.New("state key not found")
+
+// StateTypeError is returned when a state value cannot be read as the requested type
+type StateTypeError struct {
+	Key  string
+	Want reflect.Type
+	Got  interface{}
+}
+
+func (e *StateTypeError) Error() string {
+	return fmt.Sprintf("state key %q holds %T (%v), cannot be read as %s", e.Key, e.Got, e.Got, e.Want)
+}
+
+// Key is a typed handle on a state entry.
+// Declare keys once and share them between Render and HandleEvent so that a
+// typo becomes a compile error instead of a silently empty value:
+//
+//	var count = framework.NewKey[int]("count")
+type Key[T any] struct {
+	name       string
+	scope      stateScope
+	def        T
+	hasDefault bool
+}
+
+// NewKey creates a typed key in the state of the current page
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// NewAppKey creates a typed key in the app-wide store.
+// App keys survive navigation and are shared by every page, which makes them
+// the place for data such as the signed in user or UI preferences.
+func NewAppKey[T any](name string) Key[T] {
+	return Key[T]{name: name, scope: appScope}
+}
+
+// WithDefault returns a copy of the key that reads as def when the key is not set
+func (k Key[T]) WithDefault(def T) Key[T] {
+	k.def = def
+	k.hasDefault = true
+	return k
+}
+
+// Name returns the underlying state key
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Lookup reads a typed value from the state.
+// It returns ErrStateNotFound when the key is not set and a *StateTypeError
+// when the stored value cannot be converted to T.
+func Lookup[T any](k Key[T]) (T, error) {
+	value, exists := lookupState(k.scope, k.name)
+	if !exists {
+		if k.hasDefault {
+			return k.def, nil
+		}
+		var zero T
+		return zero, fmt.Errorf("%w: %q", ErrStateNotFound, k.name)
+	}
+	return convertState[T](k.name, value)
+}
+
+// Get reads a typed value from the state.
+// A missing key reads as the key default (or the zero value) and a value of an
+// incompatible type panics with a *StateTypeError.
+func Get[T any](k Key[T]) T {
+	value, err := Lookup(k)
+	if err != nil {
+		var typeErr *StateTypeError
+		if errors.As(err, &typeErr) {
+			panic(typeErr)
+		}
+	}
+	return value
+}
+
+// Set stores a typed value in the state and schedules a re-render
+func Set[T any](k Key[T], value T) {
+	setScopedState(k.scope, k.name, value)
+}
+
+// Update replaces a typed value with the result of fn applied to the current value
+func Update[T any](k Key[T], fn func(T) T) {
+	Set(k, fn(Get(k)))
+}
+
+// lookupState returns a raw state value and whether the key is set
+func lookupState(scope stateScope, key string) (interface{}, bool) {
+	if appInstance == nil {
+		return nil, false
+	}
+	return appInstance.state.get(scope, key)
+}
+
+// setScopedState queues a raw state value, the render loop commits it and re-renders.
+// It is safe to call from any goroutine.
+func setScopedState(scope stateScope, key string, value interface{}) {
+	if appInstance != nil {
+		appInstance.state.set(scope, key, value)
+	}
+}
+
+// convertState converts a raw state value to T.
+// Values are returned as is when they already have type T. Numbers are
+// converted between numeric types (including json.Number and the float64
+// produced by encoding/json) as long as no precision is lost. nil reads as
+// the zero value.
+func convertState[T any](key string, value interface{}) (T, error) {
+	var zero T
+	if value == nil {
+		return zero, nil
+	}
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+
+	want := reflect.TypeOf(&zero).Elem()
+	if converted, ok := convertNumber(value, want); ok {
+		return converted.Interface().(T), nil
+	}
+	return zero, &StateTypeError{Key: key, Want: want, Got: value}
+}
+
+// convertNumber converts a numeric value to the numeric type want without losing precision
+func convertNumber(value interface{}, want reflect.Type) (reflect.Value, bool) {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			value = i
+		} else if f, err := n.Float64(); err == nil {
+			value = f
+		} else {
+			return reflect.Value{}, false
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	if !isNumericKind(v.Kind()) || !isNumericKind(want.Kind()) {
+		return reflect.Value{}, false
+	}
+
+	switch want.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Convert(want), true
+	}
+
+	// Integer targets: reject fractions and values out of range
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || math.IsInf(f, 0) || math.IsNaN(f) {
+			return reflect.Value{}, false
+		}
+		if isUnsignedKind(want.Kind()) {
+			if f < 0 || f >= math.Ldexp(1, want.Bits()) {
+				return reflect.Value{}, false
+			}
+		} else if f < -math.Ldexp(1, want.Bits()-1) || f >= math.Ldexp(1, want.Bits()-1) {
+			return reflect.Value{}, false
+		}
+	default:
+		converted := v.Convert(want)
+		// A round trip through the target type detects overflow and sign loss
+		if !converted.Convert(v.Type()).Equal(v) || (isUnsignedKind(want.Kind()) != isUnsignedKind(v.Kind()) && signOf(v) != signOf(converted)) {
+			return reflect.Value{}, false
+		}
+		return converted, true
+	}
+	return v.Convert(want), true
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isUnsignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// signOf reports whether an integer value is negative
+func signOf(v reflect.Value) bool {
+	if isUnsignedKind(v.Kind()) {
+		return false
+	}
+	return v.Int() < 0
+}
+
+// Legacy untyped API
+
+// SetState updates the state of the current page (available to user pages)
+func SetState(key string, value interface{}) {
+	setScopedState(pageScope, key, value)
+}
+
+// GetState retrieves a value from the state of the current page (available to user pages)
+func GetState(key string) interface{} {
+	value, _ := lookupState(pageScope, key)
+	return value
+}
+
+// SetAppState updates a value of the app-wide store shared by all pages
+func SetAppState(key string, value interface{}) {
+	setScopedState(appScope, key, value)
+}
+
+// GetAppState retrieves a value from the app-wide store shared by all pages
+func GetAppState(key string) interface{} {
+	value, _ := lookupState(appScope, key)
+	return value
+}
+
+// GetStateString retrieves a state value as string
+func GetStateString(key string) string {
+	value, _ := Lookup(NewKey[string](key))
+	return value
+}
+
+// GetStateInt retrieves a state value as int.
+// Numbers of any type are accepted, such as the float64 produced by encoding/json.
+func GetStateInt(key string) int {
+	value, _ := Lookup(NewKey[int](key))
+	return value
+}
+
+// GetStateBool retrieves a state value as bool
+func GetStateBool(key string) bool {
+	value, _ := Lookup(NewKey[bool](key))
+	return value
+}
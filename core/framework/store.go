@@ -0,0 +1,228 @@
+package framework
+
+import (
+	"strings"
+	"sync"
+)
+
+// stateScope selects the store a state key lives in
+type stateScope int
+
+const (
+	// pageScope entries belong to the mounted page and are reset on navigation
+	pageScope stateScope = iota
+	// appScope entries are shared by every page for the lifetime of the app
+	appScope
+)
+
+// mutation is a state write waiting to be committed by the render loop
+type mutation struct {
+	scope      stateScope
+	key        string
+	value      interface{}
+	generation uint64 // page generation the write was made for
+
+	old    interface{} // value replaced by the write, set by commit
+	cause  string      // event being handled when the write was made
+	replay bool        // write made by Undo or Redo
+}
+
+// entry identifies a state key in its scope
+type entry struct {
+	scope stateScope
+	key   string
+}
+
+// store holds the page-scoped and app-wide state.
+// Writes can come from any goroutine: they are queued in call order and only
+// applied to the state maps when the render loop commits them, so the maps
+// are never mutated concurrently with a render. Reads see queued writes, which
+// keeps read-after-write working inside event handlers and goroutines alike.
+type store struct {
+	mu         sync.Mutex
+	page       map[string]interface{}
+	shared     map[string]interface{}
+	pending    []mutation
+	generation uint64
+	cause      string // event currently being handled, see setCause
+
+	// Every committed change of a key bumps its version, see version
+	revision uint64
+	versions map[entry]uint64
+	pageBase uint64 // revision at which the page state was last reset
+
+	// notify is called, outside the lock, every time a write is queued
+	notify func()
+}
+
+// newStore creates an empty store
+func newStore() *store {
+	return &store{
+		page:     make(map[string]interface{}),
+		shared:   make(map[string]interface{}),
+		versions: make(map[entry]uint64),
+	}
+}
+
+// scopeMap returns the map backing a state scope, the caller must hold mu
+func (s *store) scopeMap(scope stateScope) map[string]interface{} {
+	if scope == appScope {
+		return s.shared
+	}
+	return s.page
+}
+
+// set queues a write, it is safe to call from any goroutine
+func (s *store) set(scope stateScope, key string, value interface{}) {
+	s.queue(mutation{scope: scope, key: key, value: value})
+}
+
+// queue queues a mutation for the current page and notifies the render loop
+func (s *store) queue(m mutation) {
+	s.mu.Lock()
+	m.generation = s.generation
+	if m.cause == "" {
+		m.cause = s.cause
+	}
+	s.pending = append(s.pending, m)
+	notify := s.notify
+	s.mu.Unlock()
+
+	if notify != nil {
+		notify()
+	}
+}
+
+// setCause records the event whose handler is running, "" once it returns
+func (s *store) setCause(cause string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cause = cause
+}
+
+// get reads a value, including writes that are still queued
+func (s *store) get(scope stateScope, key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.pending) - 1; i >= 0; i-- {
+		m := s.pending[i]
+		if m.scope == scope && m.key == key && s.live(m) {
+			return m.value, true
+		}
+	}
+	value, exists := s.scopeMap(scope)[key]
+	return value, exists
+}
+
+// live reports whether a queued write still targets the current page, the caller must hold mu
+func (s *store) live(m mutation) bool {
+	return m.scope == appScope || m.generation == s.generation
+}
+
+// commit applies the queued writes in the order they were made and returns them.
+// It must only be called from the render loop.
+func (s *store) commit() []mutation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+	committed := make([]mutation, 0, len(s.pending))
+	for _, m := range s.pending {
+		// Writes made for a page that has since been unmounted are dropped
+		if !s.live(m) {
+			continue
+		}
+		values := s.scopeMap(m.scope)
+		m.old = values[m.key]
+		values[m.key] = m.value
+		s.bump(m.scope, m.key)
+		committed = append(committed, m)
+	}
+	s.pending = nil
+	return committed
+}
+
+// resetPage replaces the page-scoped state, disposing of the previous page's state
+func (s *store) resetPage(initial map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	s.revision++
+	s.pageBase = s.revision
+	for e := range s.versions {
+		if e.scope == pageScope {
+			delete(s.versions, e)
+		}
+	}
+	s.page = make(map[string]interface{}, len(initial))
+	for key, value := range initial {
+		s.page[key] = value
+	}
+}
+
+// seed stores initial values directly, without queueing a render
+func (s *store) seed(scope stateScope, prefix string, values map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.scopeMap(scope)
+	for key, value := range values {
+		target[prefix+key] = value
+		s.bump(scope, prefix+key)
+	}
+}
+
+// snapshot returns a copy of the committed values of a scope
+func (s *store) snapshot(scope stateScope) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[string]interface{}, len(s.scopeMap(scope)))
+	for key, value := range s.scopeMap(scope) {
+		values[key] = value
+	}
+	return values
+}
+
+// deletePrefix removes every key of a scope starting with prefix
+func (s *store) deletePrefix(scope stateScope, prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := s.scopeMap(scope)
+	for key := range values {
+		if strings.HasPrefix(key, prefix) {
+			delete(values, key)
+			s.bump(scope, key)
+		}
+	}
+}
+
+// bump records a change of key, the caller must hold mu
+func (s *store) bump(scope stateScope, key string) {
+	s.revision++
+	s.versions[entry{scope, key}] = s.revision
+}
+
+// version returns the version of a committed key, it changes every time the
+// committed value changes. ok is false while a write to the key is queued.
+func (s *store) version(scope stateScope, key string) (version uint64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.pending {
+		if m.scope == scope && m.key == key && s.live(m) {
+			return 0, false
+		}
+	}
+	version = s.versions[entry{scope, key}]
+	if scope == pageScope && version < s.pageBase {
+		// Keys untouched since the page was mounted hold the page's initial state
+		version = s.pageBase
+	}
+	return version, true
+}
@@ -0,0 +1,154 @@
+package framework
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// counterPage renders the counters written by the goroutines of the test
+type counterPage struct {
+	BasePage
+	keys []string
+}
+
+func (p *counterPage) Render() string {
+	var b strings.Builder
+	for _, key := range p.keys {
+		fmt.Fprintf(&b, "<p>%s=%d</p>", key, GetStateInt(key))
+	}
+	return b.String()
+}
+
+func TestConcurrentSetStateWithFlush(t *testing.T) {
+	const goroutines, writes = 8, 200
+
+	page := &counterPage{}
+	for i := 0; i < goroutines; i++ {
+		page.keys = append(page.keys, fmt.Sprintf("counter%d", i))
+	}
+	h := NewHeadless()
+	defer h.Close()
+	h.Mount(page)
+
+	var wg sync.WaitGroup
+	for _, key := range page.keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= writes; j++ {
+				SetState(key, j)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// The render loop keeps committing while the goroutines write
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			h.Flush()
+		}
+	}
+	h.Flush()
+
+	for _, key := range page.keys {
+		if got := GetStateInt(key); got != writes {
+			t.Errorf("%s = %d, want %d", key, got, writes)
+		}
+		if want := fmt.Sprintf("<p>%s=%d</p>", key, writes); !strings.Contains(h.HTML(), want) {
+			t.Errorf("render misses %q:\n%s", want, h.HTML())
+		}
+	}
+}
+
+func TestCommitKeepsCallOrder(t *testing.T) {
+	const goroutines, writes = 8, 100
+
+	s := newStore()
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= writes; j++ {
+				s.set(pageScope, fmt.Sprintf("key%d", i), j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	committed := s.commit()
+	if len(committed) != goroutines*writes {
+		t.Fatalf("committed %d writes, want %d", len(committed), goroutines*writes)
+	}
+	last := make(map[string]int)
+	for _, m := range committed {
+		value := m.value.(int)
+		if value != last[m.key]+1 {
+			t.Fatalf("%s: committed %d after %d", m.key, value, last[m.key])
+		}
+		if old, _ := m.old.(int); old != last[m.key] {
+			t.Fatalf("%s: old value %d, want %d", m.key, old, last[m.key])
+		}
+		last[m.key] = value
+	}
+	for key, value := range s.snapshot(pageScope) {
+		if value != writes {
+			t.Errorf("%s = %v after commit, want %d", key, value, writes)
+		}
+	}
+}
+
+func TestReadAfterWriteBeforeCommit(t *testing.T) {
+	s := newStore()
+	s.seed(pageScope, "", map[string]interface{}{"name": "initial"})
+
+	s.set(pageScope, "name", "first")
+	s.set(pageScope, "name", "second")
+	if value, _ := s.get(pageScope, "name"); value != "second" {
+		t.Errorf("get before commit = %v, want second", value)
+	}
+	if value := s.snapshot(pageScope)["name"]; value != "initial" {
+		t.Errorf("committed value before commit = %v, want initial", value)
+	}
+	if _, ok := s.version(pageScope, "name"); ok {
+		t.Error("version is available while a write is queued")
+	}
+
+	s.commit()
+	if value := s.snapshot(pageScope)["name"]; value != "second" {
+		t.Errorf("committed value = %v, want second", value)
+	}
+}
+
+func TestCommitDropsWritesOfUnmountedPage(t *testing.T) {
+	s := newStore()
+	s.resetPage(map[string]interface{}{"count": 0})
+
+	s.set(pageScope, "count", 1)
+	s.set(appScope, "theme", "dark")
+	// Navigation replaces the page before the render loop commits
+	s.resetPage(map[string]interface{}{"count": 10})
+
+	if value, _ := s.get(pageScope, "count"); value != 10 {
+		t.Errorf("get = %v, want the new page's 10", value)
+	}
+	committed := s.commit()
+	if len(committed) != 1 || committed[0].key != "theme" {
+		t.Fatalf("committed %+v, want only the app-wide write", committed)
+	}
+	if value := s.snapshot(pageScope)["count"]; value != 10 {
+		t.Errorf("count = %v after commit, want 10", value)
+	}
+	if value := s.snapshot(appScope)["theme"]; value != "dark" {
+		t.Errorf("theme = %v after commit, want dark", value)
+	}
+}
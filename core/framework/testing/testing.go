@@ -0,0 +1,310 @@
+// Package testing mounts Stencil pages and routers in an in-memory document,
+// so that they can be tested natively with plain go test.
+//
+//	import (
+//		"testing"
+//
+//		stenciltest "github.com/RafaelCoppe/Stencil-Framework/core/framework/testing"
+//	)
+//
+//	func TestCounter(t *testing.T) {
+//		h := stenciltest.Mount(t, &CounterPage{})
+//		h.Click("increment")
+//		h.AssertText("#count", "1")
+//		h.AssertState("count", 1)
+//	}
+//
+// Targets are found by the name of their data-on<event> handler first, then
+// by CSS selector: Click("save") clicks the element with data-onclick="save",
+// Click("form .btn-primary") the first element matching the selector.
+//
+// A Harness replaces the global app of the framework, tests using it must not
+// run in parallel.
+package testing
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	gotesting "testing"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
+)
+
+// Harness drives a page rendered in an in-memory document
+type Harness struct {
+	t        gotesting.TB
+	headless *framework.Headless
+}
+
+// newHarness creates a harness released when the test ends
+func newHarness(t gotesting.TB) *Harness {
+	h := &Harness{t: t, headless: framework.NewHeadless()}
+	t.Cleanup(h.headless.Close)
+	return h
+}
+
+// Mount renders page and runs its OnMount hook
+func Mount(t gotesting.TB, page framework.PageInterface) *Harness {
+	t.Helper()
+	h := newHarness(t)
+	h.headless.Mount(page)
+	return h
+}
+
+// Open renders the route registered for path, routes must be registered first:
+//
+//	app.RegisterRoutes()
+//	h := stenciltest.Open(t, "/about")
+func Open(t gotesting.TB, path string) *Harness {
+	t.Helper()
+	h := newHarness(t)
+	h.headless.Open(path)
+	return h
+}
+
+// Click clicks the target. Checkboxes are toggled and radios checked before
+// the click, which also fires their input and change events like a browser.
+// A click on an internal link navigates to it.
+func (h *Harness) Click(target string) {
+	h.t.Helper()
+	el := h.target("click", target)
+	if el.Tag() == "input" {
+		switch inputType, _ := el.Attr("type"); strings.ToLower(inputType) {
+		case "checkbox":
+			el.SetChecked(!el.Checked())
+			h.dispatch(el, "click", "input", "change")
+			return
+		case "radio":
+			el.SetChecked(true)
+			h.dispatch(el, "click", "input", "change")
+			return
+		}
+	}
+	h.dispatch(el, "click")
+}
+
+// Input types value into the target and fires an input event
+func (h *Harness) Input(target, value string) {
+	h.t.Helper()
+	el := h.target("input", target)
+	el.SetValue(value)
+	h.dispatch(el, "input")
+}
+
+// Change sets the value of the target, for example the option of a select,
+// and fires its input and change events
+func (h *Harness) Change(target, value string) {
+	h.t.Helper()
+	el := h.target("change", target)
+	el.SetValue(value)
+	h.dispatch(el, "input", "change")
+}
+
+// Select selects the options of a multiple select and fires its input and change events
+func (h *Harness) Select(target string, values ...string) {
+	h.t.Helper()
+	el := h.target("change", target)
+	el.SetSelected(values)
+	h.dispatch(el, "input", "change")
+}
+
+// Check checks or unchecks a checkbox and fires its input and change events
+func (h *Harness) Check(target string, checked bool) {
+	h.t.Helper()
+	el := h.target("change", target)
+	el.SetChecked(checked)
+	h.dispatch(el, "input", "change")
+}
+
+// Submit submits a form, found by its data-onsubmit name or a selector.
+// The target may also be an element inside the form, such as its submit
+// button: like in a browser, the event is fired on the form.
+func (h *Harness) Submit(target string) {
+	h.t.Helper()
+	el := h.target("submit", target)
+	if form := el.Form(); el.Tag() != "form" && form != nil {
+		el = form
+	}
+	h.dispatch(el, "submit")
+}
+
+// Fire fires any event on the target, the type comes from event.Type:
+//
+//	h.Fire("search", framework.EventInit{Type: "keydown", Key: "Enter"})
+func (h *Harness) Fire(target string, event framework.EventInit) {
+	h.t.Helper()
+	el := h.target(event.Type, target)
+	h.headless.Dispatch(el, event)
+}
+
+// Navigate navigates to path through the router, like a click on a link.
+// Options change the query string, see framework.NavigateOptions.
+func (h *Harness) Navigate(path string, options ...framework.NavigateOptions) {
+	h.t.Helper()
+	h.headless.Navigate(path, options...)
+}
+
+// Back goes back to the previous location, like the back button
+func (h *Harness) Back() {
+	h.t.Helper()
+	if !h.headless.Back() {
+		h.t.Fatalf("no previous location to go back to from %q", h.Path())
+	}
+}
+
+// Host returns the memory host the page runs on, for example to answer the
+// requests of an HTTP client:
+//
+//	h.Host().Fetcher = host.FetcherFunc(func(r host.Request) (*host.Response, error) { ... })
+//	client.SetFetcher(h.Host())
+func (h *Harness) Host() *framework.MemoryHost {
+	return h.headless.Host()
+}
+
+// Flush renders the state changes made outside of events, for example by a
+// goroutine started in OnMount
+func (h *Harness) Flush() {
+	h.headless.Flush()
+}
+
+// Path returns the current path, without the query string
+func (h *Harness) Path() string {
+	return h.headless.Path()
+}
+
+// Location returns the current path and query string
+func (h *Harness) Location() string {
+	return h.headless.Location()
+}
+
+// HTML returns the HTML of the last render
+func (h *Harness) HTML() string {
+	return h.headless.HTML()
+}
+
+// Find returns the first element matching selector, and fails the test if there is none
+func (h *Harness) Find(selector string) host.Element {
+	h.t.Helper()
+	found := h.FindAll(selector)
+	if len(found) == 0 {
+		h.t.Fatalf("no element matches %q in:\n%s", selector, h.HTML())
+	}
+	return found[0]
+}
+
+// FindAll returns the elements matching selector, in document order
+func (h *Harness) FindAll(selector string) []host.Element {
+	h.t.Helper()
+	found, err := h.headless.Query(selector)
+	if err != nil {
+		h.t.Fatalf("%v", err)
+	}
+	return found
+}
+
+// Text returns the trimmed text of the first element matching selector
+func (h *Harness) Text(selector string) string {
+	h.t.Helper()
+	return strings.TrimSpace(h.Find(selector).Text())
+}
+
+// AssertContains fails the test if the rendered HTML does not contain s
+func (h *Harness) AssertContains(s string) {
+	h.t.Helper()
+	if !strings.Contains(h.HTML(), s) {
+		h.t.Fatalf("the page does not contain %q:\n%s", s, h.HTML())
+	}
+}
+
+// AssertNotContains fails the test if the rendered HTML contains s
+func (h *Harness) AssertNotContains(s string) {
+	h.t.Helper()
+	if strings.Contains(h.HTML(), s) {
+		h.t.Fatalf("the page contains %q:\n%s", s, h.HTML())
+	}
+}
+
+// AssertText fails the test unless the trimmed text of the first element matching selector is want
+func (h *Harness) AssertText(selector, want string) {
+	h.t.Helper()
+	if got := h.Text(selector); got != want {
+		h.t.Fatalf("text of %q = %q, want %q", selector, got, want)
+	}
+}
+
+// AssertExists fails the test if no element matches selector
+func (h *Harness) AssertExists(selector string) {
+	h.t.Helper()
+	h.Find(selector)
+}
+
+// AssertMissing fails the test if an element matches selector
+func (h *Harness) AssertMissing(selector string) {
+	h.t.Helper()
+	if found := h.FindAll(selector); len(found) > 0 {
+		h.t.Fatalf("%d elements match %q, want none:\n%s", len(found), selector, found[0].HTML())
+	}
+}
+
+// AssertPath fails the test unless the current path is path, whatever the query string
+func (h *Harness) AssertPath(path string) {
+	h.t.Helper()
+	if got := h.Path(); got != path {
+		h.t.Fatalf("path = %q, want %q", got, path)
+	}
+}
+
+// AssertLocation fails the test unless the current path and query string are location
+func (h *Harness) AssertLocation(location string) {
+	h.t.Helper()
+	if got := h.Location(); got != location {
+		h.t.Fatalf("location = %q, want %q", got, location)
+	}
+}
+
+// AssertState fails the test unless the page state key holds want
+func (h *Harness) AssertState(key string, want interface{}) {
+	h.t.Helper()
+	if got := framework.GetState(key); !reflect.DeepEqual(got, want) {
+		h.t.Fatalf("state %q = %#v, want %#v", key, got, want)
+	}
+}
+
+// State returns the value of a typed state key of the page under test
+func State[T any](h *Harness, k framework.Key[T]) T {
+	h.t.Helper()
+	value, err := framework.Lookup(k)
+	if err != nil {
+		h.t.Fatalf("%v", err)
+	}
+	return value
+}
+
+// target finds the element to fire eventType on
+func (h *Harness) target(eventType, target string) host.Element {
+	h.t.Helper()
+	for _, selector := range []string{
+		fmt.Sprintf("[data-on%s=%q]", eventType, target),
+		fmt.Sprintf("[data-bind=%q]", target),
+	} {
+		if found, err := h.headless.Query(selector); err == nil && len(found) > 0 {
+			return found[0]
+		}
+	}
+	found, err := h.headless.Query(target)
+	if err != nil || len(found) == 0 {
+		h.t.Fatalf("no element has data-on%s=%q or matches it as a selector in:\n%s", eventType, target, h.HTML())
+	}
+	return found[0]
+}
+
+// dispatch fires events on el and renders the result
+func (h *Harness) dispatch(el host.Element, eventTypes ...string) {
+	events := make([]framework.EventInit, len(eventTypes))
+	for i, eventType := range eventTypes {
+		events[i] = framework.EventInit{Type: eventType}
+	}
+	h.headless.Dispatch(el, events...)
+}
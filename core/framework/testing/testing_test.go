@@ -0,0 +1,222 @@
+package testing_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	stenciltest "github.com/RafaelCoppe/Stencil-Framework/core/framework/testing"
+)
+
+var lastKey = framework.NewKey[string]("lastKey")
+
+// formPage has one control of each kind, bound to the state
+type formPage struct {
+	framework.BasePage
+}
+
+func (p *formPage) GetInitialState() map[string]interface{} {
+	return map[string]interface{}{
+		"count":  0,
+		"name":   "",
+		"color":  "red",
+		"agree":  false,
+		"tags":   []string{},
+		"saved":  "",
+		"status": "",
+	}
+}
+
+func (p *formPage) Render() string {
+	var tags strings.Builder
+	for _, tag := range []string{"go", "wasm", "web"} {
+		fmt.Fprintf(&tags, `<option value="%s">%s</option>`, tag, tag)
+	}
+	return fmt.Sprintf(`<div class="page">
+		<span id="count">%d</span>
+		<button class="btn btn-primary" data-onclick="increment">+1</button>
+		<input type="search" data-onkeydown="search">
+		<p class="status">%s</p>
+		<form data-onsubmit="save">
+			<input type="text" name="name" data-bind="name">
+			<select name="color" data-bind="color">
+				<option value="red">Red</option>
+				<option value="blue">Blue</option>
+			</select>
+			<input type="checkbox" name="agree" data-bind="agree">
+			<select name="tags" data-bind="tags" multiple>%s</select>
+			<button type="submit" class="btn">Save</button>
+		</form>
+		<p class="saved">%s</p>
+	</div>`, framework.GetStateInt("count"), framework.GetStateString("status"), tags.String(), framework.GetStateString("saved"))
+}
+
+func (p *formPage) HandleEvent(eventName string, event framework.Event) {
+	switch eventName {
+	case "increment":
+		framework.SetState("count", framework.GetStateInt("count")+1)
+	case "search":
+		framework.Set(lastKey, event.Key())
+	case "save":
+		values := event.FormValues()
+		framework.SetState("saved", values.Get("name")+" likes "+values.Get("color"))
+	}
+}
+
+func TestClick(t *testing.T) {
+	h := stenciltest.Mount(t, &formPage{})
+	h.AssertText("#count", "0")
+
+	h.Click("increment")
+	h.Click(".page > .btn-primary")
+
+	h.AssertText("#count", "2")
+	h.AssertState("count", 2)
+}
+
+func TestInputChangeCheckSelect(t *testing.T) {
+	h := stenciltest.Mount(t, &formPage{})
+
+	h.Input("name", "Ada")
+	h.AssertState("name", "Ada")
+
+	h.Change("color", "blue")
+	h.AssertState("color", "blue")
+
+	h.Check("agree", true)
+	h.AssertState("agree", true)
+	h.Click("input[type=checkbox]")
+	h.AssertState("agree", false)
+
+	h.Select("tags", "go", "web")
+	h.AssertState("tags", []string{"go", "web"})
+}
+
+func TestSubmit(t *testing.T) {
+	h := stenciltest.Mount(t, &formPage{})
+	h.Input("name", "Ada")
+	h.Change("color", "blue")
+
+	h.Submit("save")
+	h.AssertText(".saved", "Ada likes blue")
+
+	// Submitting through the button inside the form
+	h.Input("name", "Grace")
+	h.Submit("form button[type=submit]")
+	h.AssertText(".saved", "Grace likes blue")
+}
+
+func TestFireAndTypedState(t *testing.T) {
+	h := stenciltest.Mount(t, &formPage{})
+
+	h.Fire("search", framework.EventInit{Type: "keydown", Key: "Enter"})
+
+	if got := stenciltest.State(h, lastKey); got != "Enter" {
+		t.Errorf("lastKey = %q, want Enter", got)
+	}
+}
+
+func TestFlushAppliesGoroutineWrites(t *testing.T) {
+	h := stenciltest.Mount(t, &formPage{})
+
+	done := make(chan struct{})
+	go func() {
+		framework.SetState("status", "loaded")
+		close(done)
+	}()
+	<-done
+	h.AssertText(".status", "")
+
+	h.Flush()
+	h.AssertText(".status", "loaded")
+}
+
+func TestSelectors(t *testing.T) {
+	h := stenciltest.Mount(t, &formPage{})
+
+	for selector, want := range map[string]int{
+		"#count":                          1,
+		".btn":                            2,
+		".btn.btn-primary":                1,
+		"form > button[type=submit]":      1,
+		"div button":                      2,
+		"form input:not([type=checkbox])": 1,
+		"[data-bind^=na]":                 1,
+		"[data-bind$=s]":                  1,
+		"[data-bind*=gre]":                1,
+		"[class~=btn-primary]":            1,
+		"select[multiple] option":         3,
+		"option[value=red], #count":       2,
+		"*[data-onclick]":                 1,
+		"p":                               2,
+		"form > option":                   0,
+	} {
+		if got := len(h.FindAll(selector)); got != want {
+			t.Errorf("%q matches %d elements, want %d", selector, got, want)
+		}
+	}
+
+	h.AssertExists("select[name=color]")
+	h.AssertMissing("textarea")
+	if tag := h.Find("[data-bind=agree]").Tag(); tag != "input" {
+		t.Errorf("Find returned a %q element, want input", tag)
+	}
+	h.AssertContains(`data-onsubmit="save"`)
+	h.AssertNotContains("data-onreset")
+}
+
+// navPage links to the other pages and may refuse to be left
+type navPage struct {
+	framework.BasePage
+	title string
+	guard bool
+}
+
+func (p *navPage) Render() string {
+	return `<h1>` + p.title + `</h1><a href="/about">About</a> <a href="/contact?from=home">Contact</a>`
+}
+
+func (p *navPage) BeforeLeave(to string) bool {
+	return !p.guard
+}
+
+func registerNavRoutes() {
+	framework.RegisterRoute("/", func() framework.PageInterface { return &navPage{title: "Home"} })
+	framework.RegisterRoute("/about", func() framework.PageInterface { return &navPage{title: "About"} })
+	framework.RegisterRoute("/contact", func() framework.PageInterface { return &navPage{title: "Contact"} })
+	framework.RegisterRoute("/locked", func() framework.PageInterface { return &navPage{title: "Locked", guard: true} })
+}
+
+func TestNavigateAndBack(t *testing.T) {
+	registerNavRoutes()
+	h := stenciltest.Open(t, "/")
+	h.AssertText("h1", "Home")
+
+	h.Click(`a[href="/about"]`)
+	h.AssertPath("/about")
+	h.AssertText("h1", "About")
+
+	h.Navigate("/contact?from=home")
+	h.AssertPath("/contact")
+	h.AssertLocation("/contact?from=home")
+	h.AssertText("h1", "Contact")
+
+	h.Back()
+	h.AssertPath("/about")
+	h.AssertText("h1", "About")
+
+	h.Back()
+	h.AssertPath("/")
+	h.AssertText("h1", "Home")
+}
+
+func TestLeaveGuard(t *testing.T) {
+	registerNavRoutes()
+	h := stenciltest.Open(t, "/locked")
+
+	h.Navigate("/about")
+
+	h.AssertPath("/locked")
+	h.AssertText("h1", "Locked")
+}
@@ -0,0 +1,338 @@
+package framework
+
+import (
+	"html"
+	"strings"
+)
+
+// nodeKind identifies the type of a virtual DOM node
+type nodeKind int
+
+const (
+	elementNode nodeKind = iota
+	textNode
+	commentNode
+)
+
+// vattr represents a single attribute of a virtual element
+type vattr struct {
+	name  string
+	value string
+}
+
+// vnode is a lightweight representation of a DOM node parsed from Render output
+type vnode struct {
+	kind     nodeKind
+	tag      string // lowercase tag name, elements only
+	attrs    []vattr
+	text     string // text or comment content
+	children []*vnode
+}
+
+// voidElements lists the elements that never have children or a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements lists the elements whose content is not parsed as HTML
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// implicitClose lists, for an opening tag, the open elements it implicitly closes
+var implicitClose = map[string][]string{
+	"li":     {"li"},
+	"option": {"option"},
+	"tr":     {"td", "th", "tr"},
+	"td":     {"td", "th"},
+	"th":     {"td", "th"},
+	"p":      {"p"},
+	"div":    {"p"},
+	"ul":     {"p"},
+	"ol":     {"p"},
+	"table":  {"p"},
+	"h1":     {"p"},
+	"h2":     {"p"},
+	"h3":     {"p"},
+	"h4":     {"p"},
+	"h5":     {"p"},
+	"h6":     {"p"},
+}
+
+// getAttr returns the value of an attribute and whether it is present
+func (n *vnode) getAttr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// key returns the reconciliation key of an element (data-key attribute)
+func (n *vnode) key() string {
+	if n.kind != elementNode {
+		return ""
+	}
+	k, _ := n.getAttr("data-key")
+	return k
+}
+
+// parseHTML parses an HTML fragment into a list of virtual DOM nodes
+func parseHTML(src string) []*vnode {
+	root := &vnode{kind: elementNode}
+	stack := []*vnode{root}
+	current := func() *vnode { return stack[len(stack)-1] }
+
+	appendText := func(text string) {
+		if text == "" {
+			return
+		}
+		parent := current()
+		if n := len(parent.children); n > 0 && parent.children[n-1].kind == textNode {
+			parent.children[n-1].text += text
+			return
+		}
+		parent.children = append(parent.children, &vnode{kind: textNode, text: text})
+	}
+
+	i := 0
+	for i < len(src) {
+		lt := strings.IndexByte(src[i:], '<')
+		if lt < 0 {
+			appendText(html.UnescapeString(src[i:]))
+			break
+		}
+		if lt > 0 {
+			appendText(html.UnescapeString(src[i : i+lt]))
+			i += lt
+		}
+
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				current().children = append(current().children, &vnode{kind: commentNode, text: rest[4:]})
+				i = len(src)
+				continue
+			}
+			current().children = append(current().children, &vnode{kind: commentNode, text: rest[4 : 4+end]})
+			i += 4 + end + 3
+
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			// Doctype and processing instructions are not part of the fragment
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				i = len(src)
+				continue
+			}
+			i += end + 1
+
+		case strings.HasPrefix(rest, "</"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				appendText(rest)
+				i = len(src)
+				continue
+			}
+			name := strings.ToLower(strings.TrimSpace(rest[2:end]))
+			i += end + 1
+			// Pop up to the matching open element, ignore stray end tags
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].tag == name {
+					stack = stack[:j]
+					break
+				}
+			}
+
+		default:
+			node, selfClosing, consumed := parseStartTag(rest)
+			if node == nil {
+				appendText("<")
+				i++
+				continue
+			}
+			i += consumed
+
+			for _, closes := range implicitClose[node.tag] {
+				if len(stack) > 1 && current().tag == closes {
+					stack = stack[:len(stack)-1]
+				}
+			}
+
+			parent := current()
+			parent.children = append(parent.children, node)
+
+			if voidElements[node.tag] || selfClosing {
+				continue
+			}
+
+			if rawTextElements[node.tag] {
+				closeTag := "</" + node.tag
+				end := strings.Index(strings.ToLower(src[i:]), closeTag)
+				if end < 0 {
+					end = len(src) - i
+				}
+				content := src[i : i+end]
+				if node.tag == "textarea" || node.tag == "title" {
+					content = html.UnescapeString(content)
+				}
+				if content != "" {
+					node.children = []*vnode{{kind: textNode, text: content}}
+				}
+				i += end
+				if gt := strings.IndexByte(src[i:], '>'); gt >= 0 {
+					i += gt + 1
+				}
+				continue
+			}
+
+			stack = append(stack, node)
+		}
+	}
+
+	return root.children
+}
+
+// parseStartTag parses an opening tag at the start of s
+func parseStartTag(s string) (node *vnode, selfClosing bool, consumed int) {
+	if len(s) < 2 || !isLetter(s[1]) {
+		return nil, false, 0
+	}
+	i := 1
+	start := i
+	for i < len(s) && isTagNameChar(s[i]) {
+		i++
+	}
+	node = &vnode{kind: elementNode, tag: strings.ToLower(s[start:i])}
+
+	for i < len(s) {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return node, selfClosing, i + 1
+		}
+		if s[i] == '/' {
+			selfClosing = true
+			i++
+			continue
+		}
+		selfClosing = false
+
+		nameStart := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(s[nameStart:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				i++
+				valueStart := i
+				for i < len(s) && s[i] != quote {
+					i++
+				}
+				value = s[valueStart:i]
+				if i < len(s) {
+					i++
+				}
+			} else {
+				valueStart := i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[valueStart:i]
+			}
+		}
+
+		if name == "" {
+			i++
+			continue
+		}
+		if _, exists := node.getAttr(name); !exists {
+			node.attrs = append(node.attrs, vattr{name: name, value: html.UnescapeString(value)})
+		}
+	}
+
+	// Unterminated tag: treat the rest of the input as consumed
+	return node, selfClosing, len(s)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isTagNameChar(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// renderNodes serializes virtual DOM nodes back to HTML
+func renderNodes(nodes []*vnode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		n.render(&b, false)
+	}
+	return b.String()
+}
+
+func (n *vnode) render(b *strings.Builder, raw bool) {
+	switch n.kind {
+	case textNode:
+		if raw {
+			b.WriteString(n.text)
+		} else {
+			b.WriteString(html.EscapeString(n.text))
+		}
+	case commentNode:
+		b.WriteString("<!--" + n.text + "-->")
+	case elementNode:
+		b.WriteString("<" + n.tag)
+		for _, a := range n.attrs {
+			b.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+		}
+		b.WriteString(">")
+		if voidElements[n.tag] {
+			return
+		}
+		// Script and style content is not escaped, like the parser does not unescape it
+		raw := n.tag == "script" || n.tag == "style"
+		for _, child := range n.children {
+			child.render(b, raw)
+		}
+		b.WriteString("</" + n.tag + ">")
+	}
+}
+
+// textContent returns the text of a node and its descendants
+func (n *vnode) textContent() string {
+	if n.kind == textNode {
+		return n.text
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		if child.kind != commentNode {
+			b.WriteString(child.textContent())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package framework
+
+import (
+	"reflect"
+	"sync"
+)
+
+// watcher is a callback registered with Watch
+type watcher struct {
+	entry entry
+	fn    func(old, new interface{})
+}
+
+// watchers holds the registered watchers, they outlive app instances so that
+// app-wide keys can be watched before Run
+var watchers struct {
+	sync.Mutex
+	list []*watcher
+}
+
+// Watch calls fn after every committed change of the key, with the previous and the new value.
+// Writes made during the same frame are coalesced: fn runs once with the value
+// before the first write and after the last one, and not at all if they are equal.
+// Watchers run on the render loop before the page is rendered, so state they
+// set is part of the same render. A panic in fn is sent to the error reporter,
+// the other watchers and the render still run. Watch returns a function that
+// stops watching.
+//
+// Watchers of page keys are stopped when the page is unmounted, register them in OnMount:
+//
+//	func (p *EditorPage) OnMount() {
+//		framework.Watch(draft, func(old, new string) { saveDraft(new) })
+//	}
+func Watch[T any](k Key[T], fn func(old, new T)) (stop func()) {
+	w := &watcher{
+		entry: k.stateEntry(),
+		fn: func(old, new interface{}) {
+			// Values of another type read as the zero value, like GetStateInt and friends
+			oldValue, _ := convertState[T](k.name, old)
+			newValue, _ := convertState[T](k.name, new)
+			fn(oldValue, newValue)
+		},
+	}
+
+	watchers.Lock()
+	watchers.list = append(watchers.list, w)
+	watchers.Unlock()
+
+	return func() { removeWatchers(func(other *watcher) bool { return other == w }) }
+}
+
+// removeWatchers unregisters the watchers matched by drop
+func removeWatchers(drop func(w *watcher) bool) {
+	watchers.Lock()
+	defer watchers.Unlock()
+
+	kept := watchers.list[:0:0]
+	for _, w := range watchers.list {
+		if !drop(w) {
+			kept = append(kept, w)
+		}
+	}
+	watchers.list = kept
+}
+
+// stopPageWatchers unregisters the watchers of page-scoped keys
+func stopPageWatchers() {
+	removeWatchers(func(w *watcher) bool { return w.entry.scope == pageScope })
+}
+
+// notifyWatchers runs the watchers of the keys changed by a commit
+func notifyWatchers(committed []mutation) {
+	if len(committed) == 0 {
+		return
+	}
+
+	watchers.Lock()
+	list := append([]*watcher(nil), watchers.list...)
+	watchers.Unlock()
+	if len(list) == 0 {
+		return
+	}
+
+	// Coalesce the writes to each key, in the order the keys were first written
+	type change struct{ old, new interface{} }
+	var order []entry
+	changes := make(map[entry]*change)
+	for _, m := range committed {
+		e := entry{m.scope, m.key}
+		if c, exists := changes[e]; exists {
+			c.new = m.value
+			continue
+		}
+		changes[e] = &change{old: m.old, new: m.value}
+		order = append(order, e)
+	}
+
+	for _, e := range order {
+		c := changes[e]
+		if reflect.DeepEqual(c.old, c.new) {
+			continue
+		}
+		for _, w := range list {
+			if w.entry == e {
+				// Watchers run from the frame, outside the boundaries of the page: a panic is only reported
+				protect(ErrorInfo{Phase: "watch", Key: keyLabel(e.key)}, func() {
+					w.fn(c.old, c.new)
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package framework
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// watchedPage renders a counter watched by the test
+type watchedPage struct {
+	BasePage
+}
+
+func (p *watchedPage) GetInitialState() map[string]interface{} {
+	return map[string]interface{}{"count": 0}
+}
+
+func (p *watchedPage) Render() string {
+	return fmt.Sprintf(`<p id="count">%d</p>`, GetStateInt("count"))
+}
+
+func TestPanickingWatcherIsReported(t *testing.T) {
+	var reported []ErrorInfo
+	SetErrorReporter(func(info ErrorInfo) { reported = append(reported, info) })
+	defer SetErrorReporter(nil)
+
+	h := NewHeadless()
+	defer h.Close()
+	h.Mount(&watchedPage{})
+
+	count := NewKey[int]("count")
+	Watch(count, func(old, new int) { panic("watcher failed") })
+	var seen []int
+	Watch(count, func(old, new int) { seen = append(seen, new) })
+
+	SetState("count", 1)
+	h.Flush()
+
+	if len(reported) != 1 || reported[0].Phase != "watch" || reported[0].Key != "count" {
+		t.Fatalf("reported %+v, want one watch panic of count", reported)
+	}
+	if len(seen) != 1 || seen[0] != 1 {
+		t.Errorf("the other watcher saw %v, want [1]", seen)
+	}
+	if !strings.Contains(h.HTML(), `<p id="count">1</p>`) {
+		t.Errorf("the page was not rendered after the panic:\n%s", h.HTML())
+	}
+
+	// The watcher stays registered and keeps being reported
+	SetState("count", 2)
+	h.Flush()
+	if len(reported) != 2 {
+		t.Errorf("reported %d panics after the second change, want 2", len(reported))
+	}
+}
@@ -1,12 +1,11 @@
-//go:build js && wasm
-
 package http
 
 import (
 	"encoding/json"
 	"fmt"
-	"syscall/js"
 	"time"
+
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework/host"
 )
 
 // Client représente un client HTTP pour WASM
@@ -14,6 +13,9 @@ type Client struct {
 	BaseURL string
 	Headers map[string]string
 	Timeout time.Duration
+	// Fetcher envoie les requêtes : fetch() dans le navigateur et net/http en natif
+	// par défaut (host.DefaultFetcher). Un host.FetcherFunc permet de simuler une API dans les tests.
+	Fetcher host.Fetcher
 }
 
 // Response représente une réponse HTTP
@@ -51,6 +53,12 @@ func (c *Client) SetContentType(contentType string) *Client {
 	return c
 }
 
+// SetFetcher configure l'envoi des requêtes, par exemple avec l'hôte de l'application
+func (c *Client) SetFetcher(fetcher host.Fetcher) *Client {
+	c.Fetcher = fetcher
+	return c
+}
+
 // SetTimeout configure le timeout
 func (c *Client) SetTimeout(timeout time.Duration) *Client {
 	c.Timeout = timeout
@@ -170,79 +178,64 @@ func (c *Client) DELETE(endpoint string, queryParams ...map[string]string) *Resp
 	return c.makeRequest("DELETE", url, nil)
 }
 
-// makeRequest effectue la requête HTTP via JavaScript
+// makeRequest effectue la requête HTTP via le Fetcher du client
 func (c *Client) makeRequest(method, url string, body interface{}) *Response {
-	// Créer les options de la requête
-	options := js.Global().Get("Object").New()
-	options.Set("method", method)
-
-	// Ajouter les headers
-	headers := js.Global().Get("Object").New()
+	request := host.Request{
+		Method:  method,
+		URL:     url,
+		Headers: make(map[string]string, len(c.Headers)+1),
+		Timeout: c.Timeout,
+	}
 	for key, value := range c.Headers {
-		headers.Set(key, value)
+		request.Headers[key] = value
 	}
-	options.Set("headers", headers)
 
 	// Ajouter le corps si nécessaire
 	if body != nil {
-		switch v := body.(type) {
-		case string:
-			options.Set("body", v)
-		case []byte:
-			options.Set("body", string(v))
-		default:
-			jsonBody, err := json.Marshal(body)
-			if err != nil {
-				return &Response{Error: fmt.Errorf("erreur de sérialisation JSON: %w", err)}
-			}
-			options.Set("body", string(jsonBody))
-			if c.Headers["Content-Type"] == "" {
-				headers.Set("Content-Type", "application/json")
-			}
+		data, isJSON, err := encodeBody(body)
+		if err != nil {
+			return &Response{Error: err}
+		}
+		request.Body = []byte(data)
+		if isJSON && c.Headers["Content-Type"] == "" {
+			request.Headers["Content-Type"] = "application/json"
 		}
 	}
 
-	// Canal pour recevoir la réponse
-	responseChan := make(chan *Response, 1)
-
-	// Faire l'appel fetch
-	promise := js.Global().Call("fetch", url, options)
-
-	// Gérer la promesse
-	promise.Call("then", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		response := args[0]
+	fetcher := c.Fetcher
+	if fetcher == nil {
+		fetcher = host.DefaultFetcher
+	}
+	response, err := fetcher.Fetch(request)
+	if err != nil {
+		return &Response{Error: fmt.Errorf("erreur de requête: %w", err)}
+	}
 
-		// Créer la réponse
-		resp := &Response{
-			StatusCode: response.Get("status").Int(),
-			Headers:    make(map[string]string),
-		}
+	resp := &Response{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Body:       response.Body,
+	}
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]string)
+	}
+	return resp
+}
 
-		// Lire le texte de la réponse
-		textPromise := response.Call("text")
-		textPromise.Call("then", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			text := args[0].String()
-			resp.Body = []byte(text)
-			responseChan <- resp
-			return nil
-		}))
-
-		return nil
-	})).Call("catch", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		errMsg := "Erreur de requête"
-		if len(args) > 0 {
-			errMsg = args[0].String()
+// encodeBody sérialise le corps d'une requête.
+// Les string et []byte sont envoyés tels quels, les autres valeurs en JSON.
+func encodeBody(body interface{}) (data string, isJSON bool, err error) {
+	switch v := body.(type) {
+	case string:
+		return v, false, nil
+	case []byte:
+		return string(v), false, nil
+	default:
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return "", false, fmt.Errorf("erreur de sérialisation JSON: %w", err)
 		}
-		responseChan <- &Response{Error: fmt.Errorf("erreur de requête: %s", errMsg)}
-		return nil
-	}))
-
-	// Attendre la réponse avec timeout
-	select {
-	case resp := <-responseChan:
-		return resp
-	case <-time.After(c.Timeout):
-		return &Response{Error: fmt.Errorf("timeout de la requête")}
+		return string(jsonBody), true, nil
 	}
 }
 
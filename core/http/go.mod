@@ -1,3 +1,9 @@
 module github.com/RafaelCoppe/Stencil-Framework/core/http
 
 go 1.24.1
+
+require github.com/RafaelCoppe/Stencil-Framework/core/framework v0.0.0-00010101000000-000000000000
+
+require github.com/RafaelCoppe/Stencil-Go v1.1.0 // indirect
+
+replace github.com/RafaelCoppe/Stencil-Framework/core/framework => ../framework
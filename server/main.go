@@ -0,0 +1,32 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"flag"
+	"log"
+	nethttp "net/http"
+
+	"github.com/RafaelCoppe/Stencil-Framework/app"
+	"github.com/RafaelCoppe/Stencil-Framework/core/framework"
+	"github.com/RafaelCoppe/Stencil-Framework/core/http"
+)
+
+// Serveur de rendu côté serveur : chaque route est pré-rendue dans index.html,
+// puis l'application WebAssembly prend le relais dans le navigateur.
+func main() {
+	addr := flag.String("addr", ":8080", "adresse d'écoute")
+	flag.Parse()
+
+	// Même configuration que main.go, pour les pages qui appellent l'API pendant le rendu
+	http.InitJSONPlaceholder()
+	app.RegisterRoutes()
+
+	handler, err := framework.NewSSRHandler()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	log.Printf("🌐 Rendu serveur sur http://localhost%s", *addr)
+	log.Fatal(nethttp.ListenAndServe(*addr, handler))
+}